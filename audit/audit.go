@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/ben-han-cn/gorest/auth"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+)
+
+type Parent struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+}
+
+type Record struct {
+	Time      time.Time   `json:"time"`
+	User      string      `json:"user,omitempty"`
+	Groups    []string    `json:"groups,omitempty"`
+	Verb      string      `json:"verb"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Kind      string      `json:"kind"`
+	ID        string      `json:"id,omitempty"`
+	Parents   []Parent    `json:"parents,omitempty"`
+	Action    string      `json:"action,omitempty"`
+	Input     interface{} `json:"input,omitempty"`
+	Status    int         `json:"status"`
+	ErrorCode string      `json:"errorCode,omitempty"`
+	Latency   int64       `json:"latencyMs"`
+}
+
+type Sink interface {
+	Write(*Record) error
+}
+
+//record create, update, delete and action requests, the user is got from
+//auth, so the middleware should run after the authentication middleware
+//to record who sends the request. audit never fails the request, error
+//returned by sink is ignored. request which panics is recorded as
+//ServerError, and the panic is passed on. request rejected when create
+//context like unknown resource or invalid body doesn't reach middleware,
+//so it isn't recorded
+func Middleware(sink Sink) resource.Middleware {
+	return func(ctx *resource.Context, next func() *goresterr.APIError) (err *goresterr.APIError) {
+		verb := ctx.GetVerb()
+		if ctx.Resource == nil || verb == resource.VerbGet || verb == resource.VerbList {
+			return next()
+		}
+
+		start := time.Now()
+		panicked := true
+		defer func() {
+			if panicked {
+				err = goresterr.NewAPIError(goresterr.ServerError, "panic when handle request")
+			}
+			record := newRecord(ctx, verb, err)
+			record.Time = start
+			record.Latency = time.Since(start).Milliseconds()
+			sink.Write(record)
+		}()
+		err = next()
+		panicked = false
+		return err
+	}
+}
+
+func newRecord(ctx *resource.Context, verb string, err *goresterr.APIError) *Record {
+	r := ctx.Resource
+	record := &Record{
+		Verb:   verb,
+		Method: ctx.Method,
+		Path:   ctx.Request.URL.Path,
+		Kind:   r.GetType(),
+		ID:     r.GetID(),
+	}
+
+	if user := auth.GetUser(ctx); user != nil {
+		record.User = user.Name
+		record.Groups = user.Groups
+	}
+
+	for _, ancestor := range resource.GetAncestors(r) {
+		record.Parents = append(record.Parents, Parent{
+			Kind: ancestor.GetType(),
+			ID:   ancestor.GetID(),
+		})
+	}
+
+	//resource of the request is replaced by the patched one for patch
+	var input interface{}
+	if action := r.GetAction(); action != nil {
+		record.Action = action.Name
+		input = action.Input
+	} else if verb == resource.VerbCreate || verb == resource.VerbUpdate {
+		input = r
+	}
+	if input != nil {
+		if redacted, err := resourcefield.Redact(input); err == nil {
+			record.Input = redacted
+		}
+	}
+
+	if err != nil {
+		record.Status = err.Status
+		record.ErrorCode = err.Code
+	} else {
+		record.Status, _ = ctx.GetResponse()
+	}
+	return record
+}
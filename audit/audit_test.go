@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest"
+	"github.com/ben-han-cn/gorest/auth"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+)
+
+type Cluster struct {
+	resource.ResourceBase
+	Name string `json:"name"`
+}
+
+type Login struct {
+	User     string `json:"user"`
+	Password string `json:"password" rest:"sensitive=true"`
+}
+
+type Node struct {
+	resource.ResourceBase
+	Address string `json:"address"`
+}
+
+func (n Node) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+func (n Node) CreateAction(name string) *resource.Action {
+	if name == "login" {
+		return &resource.Action{Name: name, Input: &Login{}}
+	}
+	return nil
+}
+
+type clusterHandler struct{}
+
+func (h *clusterHandler) Get(ctx *resource.Context) resource.Resource {
+	return ctx.Resource
+}
+
+type nodeHandler struct{}
+
+func (h *nodeHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	n := ctx.Resource.(*Node)
+	n.SetID(n.Address)
+	return n, nil
+}
+
+func (h *nodeHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	return goresterr.NewAPIError(goresterr.NotFound, "node doesn't exist")
+}
+
+func (h *nodeHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	panic("update node")
+}
+
+func (h *nodeHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	return nil, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	version := &resource.APIVersion{Group: "testing", Version: "v1"}
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(version, Node{}, &nodeHandler{})
+	s := gorest.NewAPIServer(mgr)
+	s.UseMiddleware(auth.Middleware(auth.NewBasicAuthenticator(auth.DefaultRealm, map[string]auth.BasicCredential{
+		"ben": auth.BasicCredential{Password: "ben", Groups: []string{"ops"}},
+	})))
+	records := NewRingBuffer(2)
+	s.UseMiddleware(Middleware(records))
+
+	for _, r := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/apis/testing/v1/clusters/c1", ""},
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/nodes", `{"address":"1.1.1.1"}`},
+		{http.MethodDelete, "/apis/testing/v1/clusters/c1/nodes/n1", ""},
+		{http.MethodPost, "/apis/testing/v1/clusters/c1/nodes/n1?action=login", `{"user":"admin","password":"secret"}`},
+	} {
+		req, _ := http.NewRequest(r.method, r.path, bytes.NewBufferString(r.body))
+		req.SetBasicAuth("ben", "ben")
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rs := records.Records()
+	ut.Equal(t, len(rs), 2)
+	del, login := rs[0], rs[1]
+	ut.Equal(t, del.User, "ben")
+	ut.Equal(t, del.Verb, "delete")
+	ut.Equal(t, del.Path, "/apis/testing/v1/clusters/c1/nodes/n1")
+	ut.Equal(t, del.Kind, "node")
+	ut.Equal(t, del.ID, "n1")
+	ut.Equal(t, del.Parents, []Parent{{Kind: "cluster", ID: "c1"}})
+	ut.Equal(t, del.Status, goresterr.NotFound.Status)
+	ut.Equal(t, del.ErrorCode, goresterr.NotFound.Code)
+
+	ut.Equal(t, login.Verb, "action:login")
+	ut.Equal(t, login.Action, "login")
+	ut.Equal(t, login.Status, http.StatusOK)
+	ut.Equal(t, login.Input, map[string]interface{}{"user": "admin", "password": resourcefield.RedactedValue})
+}
+
+func TestPanicIsRecorded(t *testing.T) {
+	version := &resource.APIVersion{Group: "testing", Version: "v1"}
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(version, Node{}, &nodeHandler{})
+	s := gorest.NewAPIServer(mgr)
+	s.SetPanicRecovery(log.New(ioutil.Discard, "", 0), false)
+	records := NewRingBuffer(1)
+	s.UseMiddleware(Middleware(records))
+
+	req, _ := http.NewRequest(http.MethodPut, "/apis/testing/v1/clusters/c1/nodes/n1", bytes.NewBufferString(`{"address":"1.1.1.1"}`))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServerError.Status)
+
+	rs := records.Records()
+	ut.Equal(t, len(rs), 1)
+	ut.Equal(t, rs[0].Verb, "update")
+	ut.Equal(t, rs[0].ID, "n1")
+	ut.Equal(t, rs[0].Status, goresterr.ServerError.Status)
+	ut.Equal(t, rs[0].ErrorCode, goresterr.ServerError.Code)
+}
+
+func TestFileSink(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileSink(path, 200, 2)
+	ut.Assert(t, err == nil, "")
+	for i := 0; i < 10; i++ {
+		ut.Assert(t, sink.Write(&Record{Verb: "delete", Kind: "node", ID: "n1"}) == nil, "")
+	}
+	ut.Assert(t, sink.Close() == nil, "")
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		f, err := os.Open(name)
+		ut.Assert(t, err == nil, "%s should exist", name)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record Record
+			ut.Assert(t, json.Unmarshal(scanner.Bytes(), &record) == nil, "")
+			ut.Equal(t, record.ID, "n1")
+		}
+		f.Close()
+		info, _ := os.Stat(name)
+		ut.Assert(t, info.Size() <= 200, "")
+	}
+	_, err = os.Stat(path + ".3")
+	ut.Assert(t, os.IsNotExist(err), "")
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+//write records as json lines, when the file size exceeds maxSize, it's
+//renamed to file.1, and the old backups are shifted, at most maxBackups
+//files are kept
+type FileSink struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+var _ Sink = &FileSink{}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) Write(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("audit file %s is closed", s.path)
+	}
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i > 0; i-- {
+			os.Rename(backupName(s.path, i), backupName(s.path, i+1))
+		}
+		if err := os.Rename(s.path, backupName(s.path, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+func (s *FileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+//keep the latest records in memory
+type RingBuffer struct {
+	lock    sync.Mutex
+	records []*Record
+	next    int
+	full    bool
+}
+
+var _ Sink = &RingBuffer{}
+
+func NewRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{
+		records: make([]*Record, size),
+	}
+}
+
+func (b *RingBuffer) Write(record *Record) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(b.records) == 0 {
+		return nil
+	}
+
+	b.records[b.next] = record
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+	return nil
+}
+
+//records from the oldest to the latest
+func (b *RingBuffer) Records() []*Record {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.full == false {
+		return append([]*Record{}, b.records[:b.next]...)
+	}
+	return append(append([]*Record{}, b.records[b.next:]...), b.records[:b.next]...)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"net/http"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+const (
+	UserKey            = "gorest.auth.user"
+	AuthorizationKey   = "Authorization"
+	WWWAuthenticateKey = "WWW-Authenticate"
+	DefaultRealm       = "gorest"
+	bearerScheme       = "Bearer "
+)
+
+type User struct {
+	Name   string
+	Groups []string
+	Claims map[string]interface{}
+}
+
+//user is stored in context of each request and may be changed by the
+//middlewares, so authenticator returns a new user for every request
+func (u *User) clone() *User {
+	c := &User{
+		Name:   u.Name,
+		Groups: append([]string(nil), u.Groups...),
+	}
+	if u.Claims != nil {
+		c.Claims = make(map[string]interface{}, len(u.Claims))
+		for k, v := range u.Claims {
+			c.Claims[k] = v
+		}
+	}
+	return c
+}
+
+type Authenticator interface {
+	//nil user and nil error are returned if request doesn't carry
+	//the credential supported by the authenticator, error is returned
+	//if credential is invalid
+	Authenticate(*http.Request) (*User, error)
+	//value of WWW-Authenticate header
+	Challenge() string
+}
+
+//authenticators are tried in order, the first user returned is stored in
+//context, since several authenticators may accept same kind of credential
+//like bearer token, request is rejected with 401 only if all of them fail
+func Middleware(authenticators ...Authenticator) resource.Middleware {
+	return func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		message := "authentication is required"
+		failed := false
+		for _, authenticator := range authenticators {
+			user, err := authenticator.Authenticate(ctx.Request)
+			if err != nil {
+				if failed == false {
+					message = err.Error()
+					failed = true
+				}
+			} else if user != nil {
+				ctx.Set(UserKey, user)
+				return next()
+			}
+		}
+		return unauthorized(ctx, authenticators, message)
+	}
+}
+
+func unauthorized(ctx *resource.Context, authenticators []Authenticator, message string) *goresterr.APIError {
+	for _, authenticator := range authenticators {
+		ctx.Response.Header().Add(WWWAuthenticateKey, authenticator.Challenge())
+	}
+	return goresterr.NewAPIError(goresterr.Unauthorized, message)
+}
+
+//return nil if request isn't authenticated
+func GetUser(ctx *resource.Context) *User {
+	if v, ok := ctx.Get(UserKey); ok {
+		return v.(*User)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+type Book struct {
+	resource.ResourceBase
+}
+
+type bookHandler struct {
+	user *User
+}
+
+func (h *bookHandler) List(ctx *resource.Context) interface{} {
+	h.user = GetUser(ctx)
+	return []*Book{}
+}
+
+func newTestServer(h *bookHandler, authenticators ...Authenticator) *gorest.Server {
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&resource.APIVersion{Group: "testing", Version: "v1"}, Book{}, h)
+	s := gorest.NewAPIServer(mgr)
+	s.UseMiddleware(Middleware(authenticators...))
+	return s
+}
+
+func writeTokenFile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "auth")
+	ut.Assert(t, err == nil, "")
+	file := filepath.Join(dir, "tokens.csv")
+	ut.Assert(t, ioutil.WriteFile(file, []byte(content), 0644) == nil, "")
+	return file
+}
+
+func TestMiddleware(t *testing.T) {
+	h := &bookHandler{}
+	basic := NewBasicAuthenticator(DefaultRealm, map[string]BasicCredential{
+		"ben": BasicCredential{Password: "secret", Groups: []string{"admin"}},
+	})
+	s := newTestServer(h, basic)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/books", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Unauthorized.Status)
+	ut.Equal(t, w.Header().Get(WWWAuthenticateKey), `Basic realm="gorest"`)
+	ut.Assert(t, h.user == nil, "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/books", nil)
+	req.SetBasicAuth("ben", "wrong")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Unauthorized.Status)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/books", nil)
+	req.SetBasicAuth("ben", "secret")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, h.user.Name, "ben")
+	ut.Equal(t, h.user.Groups, []string{"admin"})
+}
+
+func TestTokenFileAuthenticator(t *testing.T) {
+	file := writeTokenFile(t, "#token,user,uid,groups\nt1,ben,1001,\"admin,dev\"\nt2,joe\n")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	a, err := NewTokenFileAuthenticator(DefaultRealm, file)
+	ut.Assert(t, err == nil, "")
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	user, err := a.Authenticate(req)
+	ut.Assert(t, user == nil && err == nil, "")
+
+	req.Header.Set(AuthorizationKey, "Bearer t1")
+	user, err = a.Authenticate(req)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, user.Name, "ben")
+	ut.Equal(t, user.Groups, []string{"admin", "dev"})
+	ut.Equal(t, user.Claims["uid"], "1001")
+
+	//user returned for each request is independent
+	user.Groups[0] = "root"
+	user.Claims["uid"] = "0"
+	user, _ = a.Authenticate(req)
+	ut.Equal(t, user.Groups, []string{"admin", "dev"})
+	ut.Equal(t, user.Claims["uid"], "1001")
+
+	req.Header.Set(AuthorizationKey, "bearer t2")
+	user, err = a.Authenticate(req)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, user.Name, "joe")
+	ut.Equal(t, len(user.Groups), 0)
+
+	req.Header.Set(AuthorizationKey, "Bearer t3")
+	_, err = a.Authenticate(req)
+	ut.Assert(t, err != nil, "")
+
+	file = writeTokenFile(t, "t1,ben\nt1,joe\n")
+	defer os.RemoveAll(filepath.Dir(file))
+	_, err = NewTokenFileAuthenticator(DefaultRealm, file)
+	ut.Assert(t, err != nil, "duplicate token should be rejected")
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+type BasicCredential struct {
+	Password string
+	Groups   []string
+}
+
+type BasicAuthenticator struct {
+	realm       string
+	credentials map[string]BasicCredential
+}
+
+var _ Authenticator = &BasicAuthenticator{}
+
+//credentials is keyed by user name
+func NewBasicAuthenticator(realm string, credentials map[string]BasicCredential) *BasicAuthenticator {
+	return &BasicAuthenticator{
+		realm:       realm,
+		credentials: credentials,
+	}
+}
+
+func (a *BasicAuthenticator) Authenticate(req *http.Request) (*User, error) {
+	name, password, ok := req.BasicAuth()
+	if ok == false {
+		return nil, nil
+	}
+
+	credential, ok := a.credentials[name]
+	if ok == false || subtle.ConstantTimeCompare([]byte(credential.Password), []byte(password)) != 1 {
+		return nil, fmt.Errorf("invalid user name or password")
+	}
+	return &User{
+		Name:   name,
+		Groups: append([]string(nil), credential.Groups...),
+	}, nil
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q", a.realm)
+}
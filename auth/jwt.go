@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//key used to verify jwt is []byte for HMAC, *rsa.PublicKey for RSA and
+//*ecdsa.PublicKey for ECDSA, keys are indexed by key id, key with empty
+//id is used when token has no kid
+type KeySet map[string]interface{}
+
+type JWTAuthenticator struct {
+	realm         string
+	keys          KeySet
+	issuer        string
+	audience      string
+	usernameClaim string
+	groupsClaim   string
+	now           func() time.Time
+}
+
+var _ Authenticator = &JWTAuthenticator{}
+
+type JWTOption func(*JWTAuthenticator)
+
+func WithIssuer(issuer string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.issuer = issuer
+	}
+}
+
+func WithAudience(audience string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.audience = audience
+	}
+}
+
+//user name is got from claim sub and groups from claim groups by default
+func WithUsernameClaim(claim string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.usernameClaim = claim
+	}
+}
+
+func WithGroupsClaim(claim string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.groupsClaim = claim
+	}
+}
+
+func NewJWTAuthenticator(realm string, keys KeySet, opts ...JWTOption) *JWTAuthenticator {
+	a := &JWTAuthenticator{
+		realm:         realm,
+		keys:          keys,
+		usernameClaim: "sub",
+		groupsClaim:   "groups",
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func (a *JWTAuthenticator) Authenticate(req *http.Request) (*User, error) {
+	token, ok := bearerToken(req)
+	//opaque token is left to other authenticators
+	if ok == false || strings.Count(token, ".") != 2 {
+		return nil, nil
+	}
+
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %s", err.Error())
+	}
+
+	name, _ := claims[a.usernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("invalid token: claim %s is missing", a.usernameClaim)
+	}
+	user := &User{
+		Name:   name,
+		Claims: claims,
+	}
+	if groups, ok := claims[a.groupsClaim].([]interface{}); ok {
+		for _, g := range groups {
+			if group, ok := g.(string); ok {
+				user.Groups = append(user.Groups, group)
+			}
+		}
+	}
+	return user, nil
+}
+
+func (a *JWTAuthenticator) Challenge() string {
+	return fmt.Sprintf("Bearer realm=%q", a.realm)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (a *JWTAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header")
+	}
+
+	key, ok := a.keys[header.Kid]
+	if ok == false {
+		return nil, fmt.Errorf("unknown key %s", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature")
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed claims")
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var algHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+//ecdsa algorithm is bound to curve as well as hash
+var algCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+//algorithm must match the type of key, so token signed with HMAC can't
+//be verified by public key
+func verifySignature(alg string, key interface{}, signed, signature []byte) error {
+	hash, ok := algHashes[alg]
+	if ok == false {
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case []byte:
+		if strings.HasPrefix(alg, "HS") == false {
+			return fmt.Errorf("algorithm %s doesn't match key", alg)
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write(signed)
+		if hmac.Equal(mac.Sum(nil), signature) == false {
+			return fmt.Errorf("signature is invalid")
+		}
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "RS") == false {
+			return fmt.Errorf("algorithm %s doesn't match key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, signature); err != nil {
+			return fmt.Errorf("signature is invalid")
+		}
+	case *ecdsa.PublicKey:
+		curve, ok := algCurves[alg]
+		if ok == false || k.Curve.Params().Name != curve.Params().Name {
+			return fmt.Errorf("algorithm %s doesn't match key", alg)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("algorithm %s doesn't match key", alg)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if ecdsa.Verify(k, digest, r, s) == false {
+			return fmt.Errorf("signature is invalid")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+	exp, ok, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	} else if ok && now.After(exp) {
+		return fmt.Errorf("token is expired")
+	}
+	nbf, ok, err := timeClaim(claims, "nbf")
+	if err != nil {
+		return err
+	} else if ok && now.Before(nbf) {
+		return fmt.Errorf("token isn't valid yet")
+	}
+
+	if a.issuer != "" && claims["iss"] != a.issuer {
+		return fmt.Errorf("issuer isn't %s", a.issuer)
+	}
+
+	if a.audience != "" {
+		matched := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			matched = aud == a.audience
+		case []interface{}:
+			for _, v := range aud {
+				if v == a.audience {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched == false {
+			return fmt.Errorf("audience isn't %s", a.audience)
+		}
+	}
+	return nil
+}
+
+//time claim is optional, but it must be a number if it's present
+func timeClaim(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if ok == false {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(float64)
+	if ok == false {
+		return time.Time{}, false, fmt.Errorf("claim %s isn't a number", name)
+	}
+	return time.Unix(int64(n), 0), true, nil
+}
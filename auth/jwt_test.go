@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func signToken(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		ut.Assert(t, err == nil, "")
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		ut.Assert(t, err == nil, "")
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func authenticateToken(a Authenticator, token string) (*User, error) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(AuthorizationKey, "Bearer "+token)
+	return a.Authenticate(req)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secret := []byte("secret")
+	a := NewJWTAuthenticator(DefaultRealm, KeySet{
+		"":    secret,
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	}, WithIssuer("gorest"), WithAudience("api"))
+
+	claims := map[string]interface{}{
+		"sub":    "ben",
+		"groups": []string{"admin"},
+		"iss":    "gorest",
+		"aud":    []string{"api", "web"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+	for _, c := range []struct {
+		alg string
+		kid string
+		key interface{}
+	}{
+		{"HS256", "", secret},
+		{"RS256", "rsa", rsaKey},
+		{"ES256", "ec", ecKey},
+	} {
+		user, err := authenticateToken(a, signToken(t, c.alg, c.kid, c.key, claims))
+		ut.Assert(t, err == nil, "%s token should be valid: %v", c.alg, err)
+		ut.Equal(t, user.Name, "ben")
+		ut.Equal(t, user.Groups, []string{"admin"})
+	}
+
+	//opaque token is ignored
+	user, err := authenticateToken(a, "opaque")
+	ut.Assert(t, user == nil && err == nil, "")
+
+	//algorithm doesn't match key type
+	_, err = authenticateToken(a, signToken(t, "RS256", "", rsaKey, claims))
+	ut.Assert(t, err != nil, "")
+	for _, alg := range []string{"none", "", "S", "HS", "PS256", "HSX256", "hs256"} {
+		_, err = authenticateToken(a, signToken(t, alg, "", secret, claims))
+		ut.Assert(t, err != nil, "algorithm %s should be rejected", alg)
+	}
+	_, err = authenticateToken(a, signToken(t, "HS256", "", []byte("wrong"), claims))
+	ut.Assert(t, err != nil, "")
+	_, err = authenticateToken(a, signToken(t, "HS256", "unknown", secret, claims))
+	ut.Assert(t, err != nil, "")
+
+	//ES384 signed by P-256 key has the right length but wrong curve
+	header, _ := json.Marshal(map[string]string{"alg": "ES384", "kid": "ec"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha512.Sum384([]byte(signed))
+	r, sig, _ := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	_, err = authenticateToken(a, signed+"."+base64.RawURLEncoding.EncodeToString(signature))
+	ut.Assert(t, err != nil, "")
+
+	for k, v := range map[string]interface{}{
+		"exp": time.Now().Add(-time.Minute).Unix(),
+		"nbf": time.Now().Add(time.Minute).Unix(),
+		"iss": "other",
+		"aud": "web",
+	} {
+		invalid := make(map[string]interface{})
+		for ck, cv := range claims {
+			invalid[ck] = cv
+		}
+		invalid[k] = v
+		_, err = authenticateToken(a, signToken(t, "HS256", "", secret, invalid))
+		ut.Assert(t, err != nil, "token with invalid %s should be rejected", k)
+	}
+
+	for _, k := range []string{"exp", "nbf"} {
+		invalid := make(map[string]interface{})
+		for ck, cv := range claims {
+			invalid[ck] = cv
+		}
+		invalid[k] = "1"
+		_, err = authenticateToken(a, signToken(t, "HS256", "", secret, invalid))
+		ut.Assert(t, err != nil, "token with string %s should be rejected", k)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "auth")
+	defer os.RemoveAll(dir)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	pemFile := filepath.Join(dir, "ec.pem")
+	ioutil.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+	key, err := LoadPublicKey(pemFile)
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, key.(*ecdsa.PublicKey).Equal(&ecKey.PublicKey), "")
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+			{"kty": "oct", "kid": "hmac", "k": encode([]byte("secret"))},
+		},
+	})
+	jwksFile := filepath.Join(dir, "jwks.json")
+	ioutil.WriteFile(jwksFile, jwks, 0644)
+	keys, err := LoadJWKS(jwksFile)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(keys), 3)
+
+	a := NewJWTAuthenticator(DefaultRealm, keys)
+	claims := map[string]interface{}{"sub": "ben"}
+	_, err = authenticateToken(a, signToken(t, "RS256", "rsa", rsaKey, claims))
+	ut.Assert(t, err == nil, "")
+	_, err = authenticateToken(a, signToken(t, "ES256", "ec", ecKey, claims))
+	ut.Assert(t, err == nil, "")
+	_, err = authenticateToken(a, signToken(t, "HS256", "hmac", []byte("secret"), claims))
+	ut.Assert(t, err == nil, "")
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type TokenAuthenticator struct {
+	realm  string
+	tokens map[string]*User
+}
+
+var _ Authenticator = &TokenAuthenticator{}
+
+//each line of token file is token,user,uid,"group1,group2", uid and
+//groups are optional, uid is saved in claims
+func NewTokenFileAuthenticator(realm, file string) (*TokenAuthenticator, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	tokens := make(map[string]*User)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("read token file %s failed:%s", file, err.Error())
+		}
+
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("token file %s has invalid line %v", file, record)
+		}
+		if _, ok := tokens[record[0]]; ok {
+			return nil, fmt.Errorf("token file %s has duplicate token for user %s", file, record[1])
+		}
+
+		user := &User{Name: record[1]}
+		if len(record) > 2 && record[2] != "" {
+			user.Claims = map[string]interface{}{"uid": record[2]}
+		}
+		if len(record) > 3 && record[3] != "" {
+			user.Groups = strings.Split(record[3], ",")
+		}
+		tokens[record[0]] = user
+	}
+
+	return &TokenAuthenticator{
+		realm:  realm,
+		tokens: tokens,
+	}, nil
+}
+
+func (a *TokenAuthenticator) Authenticate(req *http.Request) (*User, error) {
+	token, ok := bearerToken(req)
+	if ok == false {
+		return nil, nil
+	}
+
+	for t, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return user.clone(), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid token")
+}
+
+func (a *TokenAuthenticator) Challenge() string {
+	return fmt.Sprintf("Bearer realm=%q", a.realm)
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get(AuthorizationKey)
+	if len(auth) <= len(bearerScheme) || strings.EqualFold(auth[:len(bearerScheme)], bearerScheme) == false {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len(bearerScheme):]), true
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+const contentType = "application/json"
+
+//parents are listed from top level resource, url of resource is
+//generated with same rules of the server, for example pod whose parents
+//are cluster c1 and namespace n1 is located at
+//{version url}/clusters/c1/namespaces/n1/pods
+type Parent struct {
+	Kind resource.ResourceKind
+	ID   string
+}
+
+//metadata of a collection, resources are decoded into the out param of list
+type Collection struct {
+	Type         string                                              `json:"type"`
+	ResourceType string                                              `json:"resourceType"`
+	Links        map[resource.ResourceLinkType]resource.ResourceLink `json:"links"`
+	Actions      map[string]resource.ResourceLink                    `json:"actions"`
+	Pagination   *resource.Pagination                                `json:"pagination"`
+}
+
+type Client struct {
+	serverURL  *url.URL
+	version    resource.APIVersion
+	httpClient *http.Client
+}
+
+//serverURL includes schema and host like http://127.0.0.1:1234
+func New(serverURL string, version resource.APIVersion) (*Client, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		serverURL:  u,
+		version:    version,
+		httpClient: http.DefaultClient,
+	}, nil
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+func (c *Client) CollectionURL(kind resource.ResourceKind, parents []Parent) string {
+	u := c.version.GetUrl()
+	for _, parent := range parents {
+		u += "/" + resource.DefaultResourceName(parent.Kind) + "/" + EscapeID(parent.ID)
+	}
+	return c.resolve(u + "/" + resource.DefaultResourceName(kind))
+}
+
+func (c *Client) ResourceURL(kind resource.ResourceKind, parents []Parent, id string) string {
+	return c.CollectionURL(kind, parents) + "/" + EscapeID(id)
+}
+
+//id is escaped as one segment of url path, "." and ".." are escaped too,
+//otherwise they are removed when the url is resolved
+func EscapeID(id string) string {
+	if id == "." || id == ".." {
+		return strings.Repeat("%2E", len(id))
+	}
+	return url.PathEscape(id)
+}
+
+//link returned by server may be relative to the server url
+func (c *Client) resolve(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return c.serverURL.ResolveReference(u).String()
+}
+
+//the created resource returned by server is decoded into r
+func (c *Client) Create(kind resource.ResourceKind, parents []Parent, r resource.Resource) error {
+	return c.Do(http.MethodPost, c.CollectionURL(kind, parents), r, r)
+}
+
+func (c *Client) Get(kind resource.ResourceKind, parents []Parent, id string, out resource.Resource) error {
+	return c.Do(http.MethodGet, c.ResourceURL(kind, parents, id), nil, out)
+}
+
+//out should be pointer to slice like *[]*Pod, query could include
+//filters, sort, fields and pagination parameters
+func (c *Client) List(kind resource.ResourceKind, parents []Parent, query url.Values, out interface{}) (*Collection, error) {
+	u := c.CollectionURL(kind, parents)
+	if len(query) > 0 {
+		u = u + "?" + query.Encode()
+	}
+	return c.ListLink(resource.ResourceLink(u), out)
+}
+
+func (c *Client) Update(kind resource.ResourceKind, parents []Parent, r resource.Resource) error {
+	return c.Do(http.MethodPut, c.ResourceURL(kind, parents, r.GetID()), r, r)
+}
+
+func (c *Client) Delete(kind resource.ResourceKind, parents []Parent, id string) error {
+	return c.Do(http.MethodDelete, c.ResourceURL(kind, parents, id), nil, nil)
+}
+
+func (c *Client) Action(kind resource.ResourceKind, parents []Parent, id, name string, input, output interface{}) error {
+	return c.Do(http.MethodPost, c.ResourceURL(kind, parents, id)+"?action="+url.QueryEscape(name), input, output)
+}
+
+func (c *Client) CollectionAction(kind resource.ResourceKind, parents []Parent, name string, input, output interface{}) error {
+	return c.Do(http.MethodPost, c.CollectionURL(kind, parents)+"?action="+url.QueryEscape(name), input, output)
+}
+
+//follow link of resource like self or one child collection
+func (c *Client) GetLink(link resource.ResourceLink, out interface{}) error {
+	return c.Do(http.MethodGet, string(link), nil, out)
+}
+
+//follow link of collection like next and prev
+func (c *Client) ListLink(link resource.ResourceLink, out interface{}) (*Collection, error) {
+	var raw struct {
+		Collection
+		Data json.RawMessage `json:"data"`
+	}
+	if err := c.Do(http.MethodGet, string(link), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	if out != nil && len(raw.Data) > 0 {
+		if err := json.Unmarshal(raw.Data, out); err != nil {
+			return nil, fmt.Errorf("decode resources failed:%s", err.Error())
+		}
+	}
+	return &raw.Collection, nil
+}
+
+//input is encoded as json body, response body is decoded into output,
+//link may be relative to server url, error returned by server is
+//*goresterr.APIError
+func (c *Client) Do(method, link string, input, output interface{}) error {
+	u := c.resolve(link)
+	var body *bytes.Buffer
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("encode request body failed:%s", err.Error())
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, u, body)
+	} else {
+		req, err = http.NewRequest(method, u, nil)
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body failed:%s", err.Error())
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr goresterr.APIError
+		if err := json.Unmarshal(data, &apiErr); err != nil || apiErr.Code == "" {
+			return &goresterr.APIError{
+				ErrorCode: goresterr.ErrorCode{Status: resp.StatusCode},
+				Type:      "error",
+				Message:   string(data),
+			}
+		}
+		return &apiErr
+	}
+
+	if output != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, output); err != nil {
+			return fmt.Errorf("decode response failed:%s", err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+var version = resource.APIVersion{
+	Group:   "testing",
+	Version: "v1",
+}
+
+type Cluster struct {
+	resource.ResourceBase
+	Name string `json:"name" rest:"required=true,minLen=1,maxLen=10"`
+}
+
+type Node struct {
+	resource.ResourceBase
+	Address string `json:"address"`
+}
+
+func (n Node) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+type Rename struct {
+	Name string `json:"name"`
+}
+
+func (c Cluster) CreateAction(name string) *resource.Action {
+	if name == "rename" {
+		return &resource.Action{Name: name, Input: &Rename{}}
+	}
+	return nil
+}
+
+type clusterHandler struct {
+	clusters []*Cluster
+}
+
+func (h *clusterHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	c := ctx.Resource.(*Cluster)
+	c.SetID(c.Name)
+	h.clusters = append(h.clusters, c)
+	return c, nil
+}
+
+func (h *clusterHandler) get(id string) *Cluster {
+	for _, c := range h.clusters {
+		if c.GetID() == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (h *clusterHandler) Get(ctx *resource.Context) resource.Resource {
+	if c := h.get(ctx.Resource.GetID()); c != nil {
+		return c
+	}
+	return nil
+}
+
+func (h *clusterHandler) List(ctx *resource.Context) interface{} {
+	return h.clusters
+}
+
+func (h *clusterHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	c := h.get(ctx.Resource.GetID())
+	if c == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "cluster doesn't exist")
+	}
+	c.Name = ctx.Resource.(*Cluster).Name
+	return c, nil
+}
+
+func (h *clusterHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	for i, c := range h.clusters {
+		if c.GetID() == ctx.Resource.GetID() {
+			h.clusters = append(h.clusters[:i], h.clusters[i+1:]...)
+			return nil
+		}
+	}
+	return goresterr.NewAPIError(goresterr.NotFound, "cluster doesn't exist")
+}
+
+func (h *clusterHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	c := h.get(ctx.Resource.GetID())
+	c.Name = ctx.Resource.GetAction().Input.(*Rename).Name
+	return c, nil
+}
+
+type nodeHandler struct{}
+
+func (h *nodeHandler) List(ctx *resource.Context) interface{} {
+	var nodes []*Node
+	for _, addr := range []string{"1.1.1.1", "2.2.2.2"} {
+		n := &Node{Address: addr}
+		n.SetID(ctx.Resource.GetParent().GetID() + "-" + addr)
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func newTestClient(t *testing.T) (*Client, func()) {
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(&version, Node{}, &nodeHandler{})
+	server := httptest.NewServer(gorest.NewAPIServer(mgr))
+	c, err := New(server.URL, version)
+	ut.Assert(t, err == nil, "")
+	return c, server.Close
+}
+
+func TestClient(t *testing.T) {
+	c, cleanup := newTestClient(t)
+	defer cleanup()
+
+	for _, name := range []string{"c1", "c2"} {
+		cluster := &Cluster{Name: name}
+		ut.Assert(t, c.Create(Cluster{}, nil, cluster) == nil, "")
+		ut.Equal(t, cluster.GetID(), name)
+		ut.Equal(t, cluster.GetType(), "cluster")
+	}
+
+	var cluster Cluster
+	ut.Assert(t, c.Get(Cluster{}, nil, "c1", &cluster) == nil, "")
+	ut.Equal(t, cluster.Name, "c1")
+
+	cluster.Name = "c11"
+	ut.Assert(t, c.Update(Cluster{}, nil, &cluster) == nil, "")
+	ut.Equal(t, cluster.Name, "c11")
+
+	var renamed Cluster
+	err := c.Action(Cluster{}, nil, "c1", "rename", &Rename{Name: "c12"}, &renamed)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, renamed.Name, "c12")
+
+	var clusters []*Cluster
+	collection, err := c.List(Cluster{}, nil, url.Values{"sort": []string{"-name"}}, &clusters)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, collection.ResourceType, "cluster")
+	ut.Equal(t, len(clusters), 2)
+	ut.Equal(t, clusters[0].Name, "c2")
+
+	var nodes []*Node
+	parents := []Parent{{Kind: Cluster{}, ID: "c2"}}
+	collection, err = c.List(Node{}, parents, url.Values{"limit": []string{"1"}}, &nodes)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(nodes), 1)
+	ut.Equal(t, *collection.Pagination.Total, 2)
+	_, err = c.ListLink(collection.Links[resource.NextLink], &nodes)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(nodes), 1)
+	ut.Equal(t, nodes[0].GetID(), "c2-2.2.2.2")
+
+	nodes = nil
+	_, err = c.ListLink(clusters[0].GetLinks()[resource.ResourceLinkType("nodes")], &nodes)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(nodes), 2)
+
+	ut.Assert(t, c.Delete(Cluster{}, nil, "c2") == nil, "")
+	err = c.Get(Cluster{}, nil, "c2", &cluster)
+	apiErr, ok := err.(*goresterr.APIError)
+	ut.Assert(t, ok, "")
+	ut.Equal(t, apiErr.ErrorCode, goresterr.NotFound)
+
+	err = c.Create(Cluster{}, nil, &Cluster{Name: "too long cluster name"})
+	apiErr, ok = err.(*goresterr.APIError)
+	ut.Assert(t, ok, "")
+	ut.Equal(t, apiErr.Status, http.StatusUnprocessableEntity)
+}
+
+func TestResourceURL(t *testing.T) {
+	c, err := New("http://127.0.0.1:1234", version)
+	ut.Assert(t, err == nil, "")
+
+	for id, expect := range map[string]string{
+		"n1":      "http://127.0.0.1:1234/apis/testing/v1/clusters/c1/nodes/n1",
+		"a/b":     "http://127.0.0.1:1234/apis/testing/v1/clusters/c1/nodes/a%2Fb",
+		"a?b#c":   "http://127.0.0.1:1234/apis/testing/v1/clusters/c1/nodes/a%3Fb%23c",
+		"..":      "http://127.0.0.1:1234/apis/testing/v1/clusters/c1/nodes/%2E%2E",
+		"../../x": "http://127.0.0.1:1234/apis/testing/v1/clusters/c1/nodes/..%2F..%2Fx",
+	} {
+		ut.Equal(t, c.ResourceURL(Node{}, []Parent{{Cluster{}, "c1"}}, id), expect)
+	}
+
+	ut.Equal(t, c.CollectionURL(Node{}, []Parent{{Cluster{}, ".."}}),
+		"http://127.0.0.1:1234/apis/testing/v1/clusters/%2E%2E/nodes")
+	ut.Equal(t, c.CollectionURL(Node{}, []Parent{{Cluster{}, "c/1"}}),
+		"http://127.0.0.1:1234/apis/testing/v1/clusters/c%2F1/nodes")
+}
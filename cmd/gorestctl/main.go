@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ben-han-cn/gorest/client"
+	"github.com/ben-han-cn/gorest/ctl"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+//global flags should be specified before command, like
+//gorestctl -server http://127.0.0.1:1234 -group zcloud.cn -version v1 get node n1 --cluster c1
+func main() {
+	var server string
+	var version resource.APIVersion
+	flag.StringVar(&server, "server", "http://127.0.0.1:1234", "url of gorest server")
+	flag.StringVar(&version.Group, "group", "", "api group")
+	flag.StringVar(&version.Version, "version", "v1", "api version")
+	flag.Parse()
+
+	if err := run(server, version, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, ctl.FormatError(err))
+		os.Exit(1)
+	}
+}
+
+func run(server string, version resource.APIVersion, args []string) error {
+	c, err := client.New(server, version)
+	if err != nil {
+		return err
+	}
+
+	resources, err := ctl.Discover(c, version)
+	if err != nil {
+		return err
+	}
+	return ctl.NewCommand(c, resources, os.Stdout).Run(args)
+}
@@ -0,0 +1,157 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Codec interface {
+	//content type set in response
+	ContentType() string
+	Marshal(interface{}) ([]byte, error)
+	//unmarshal data into generic value, object is decoded into
+	//map[string]interface{} and array into []interface{}
+	Unmarshal([]byte) (interface{}, error)
+}
+
+var (
+	JSON       Codec = &jsonCodec{}
+	PrettyJSON Codec = &jsonCodec{pretty: true}
+	YAML       Codec = &yamlCodec{}
+	MsgPack    Codec = &msgpackCodec{}
+)
+
+var codecs = make(map[string]Codec)
+
+func init() {
+	Register(JSON, "application/json")
+	Register(YAML, "application/yaml", "application/x-yaml", "text/yaml")
+	Register(MsgPack, "application/msgpack", "application/x-msgpack", "application/vnd.msgpack")
+}
+
+//register codec for media types, the codec registered later
+//will replace the old one with same media type
+func Register(c Codec, mediaTypes ...string) {
+	for _, mediaType := range mediaTypes {
+		codecs[strings.ToLower(mediaType)] = c
+	}
+}
+
+//return codec for content type, nil is returned if no codec is registered
+func ForContentType(contentType string) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	return codecs[mediaType]
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+//select codec based on accept header, JSON is used
+//if accept is empty or any type is acceptable
+func Negotiate(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return JSON, true
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+
+	//more specific media range is preferred when q is same
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+	for _, r := range ranges {
+		if r.mediaType == "*/*" || r.mediaType == "application/*" {
+			return JSON, true
+		}
+		if c, ok := codecs[r.mediaType]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func specificity(mediaType string) int {
+	if mediaType == "*/*" {
+		return 0
+	} else if strings.HasSuffix(mediaType, "/*") {
+		return 1
+	}
+	return 2
+}
+
+//convert data encoded by c into json
+func ToJSON(c Codec, data []byte) ([]byte, error) {
+	if _, ok := c.(*jsonCodec); ok {
+		return data, nil
+	}
+
+	v, err := c.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+//non-json codecs marshal the generic value which is converted from
+//json, so json tags and json marshaler of resources are respected
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var g interface{}
+	if err := decoder.Decode(&g); err != nil {
+		return nil, err
+	}
+	return normalizeNumber(g), nil
+}
+
+func normalizeNumber(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for k, e := range c {
+			c[k] = normalizeNumber(e)
+		}
+	case []interface{}:
+		for i, e := range c {
+			c[i] = normalizeNumber(e)
+		}
+	case json.Number:
+		if i, err := c.Int64(); err == nil {
+			return i
+		}
+		f, _ := c.Float64()
+		return f
+	}
+	return v
+}
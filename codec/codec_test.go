@@ -0,0 +1,99 @@
+package codec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		accept string
+		codec  Codec
+	}{
+		{"", JSON},
+		{"*/*", JSON},
+		{"application/*", JSON},
+		{"application/json", JSON},
+		{"application/yaml", YAML},
+		{"text/yaml;charset=utf-8", YAML},
+		{"application/x-msgpack", MsgPack},
+		{"text/html, application/yaml;q=0.5, application/msgpack;q=0.8", MsgPack},
+		{"application/msgpack;q=0, */*;q=0.1", JSON},
+		{"text/html, application/yaml", YAML},
+		{"*/*, application/yaml", YAML},
+		{"application/*, application/msgpack", MsgPack},
+		{"*/*;q=0.5, application/yaml;q=0.5", YAML},
+	}
+	for _, tc := range cases {
+		c, ok := Negotiate(tc.accept)
+		ut.Assert(t, ok, "negotiate %s failed", tc.accept)
+		ut.Equal(t, c, tc.codec)
+	}
+
+	for _, accept := range []string{"text/html", "application/yaml;q=0", "application/xml, text/plain"} {
+		_, ok := Negotiate(accept)
+		ut.Assert(t, ok == false, "%s shouldn't be acceptable", accept)
+	}
+}
+
+type isoTime time.Time
+
+func (t isoTime) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(t).Format(time.RFC3339) + `"`), nil
+}
+
+func (t *isoTime) UnmarshalJSON(data []byte) error {
+	tm, err := time.Parse(`"`+time.RFC3339+`"`, string(data))
+	*t = isoTime(tm)
+	return err
+}
+
+type testObject struct {
+	Name      string            `json:"name"`
+	Count     int               `json:"count"`
+	Ratio     float64           `json:"ratio"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Numbers   []uint32          `json:"numbers"`
+	Timestamp isoTime           `json:"timestamp"`
+	ignored   int
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	obj := testObject{
+		Name:      "n1",
+		Count:     10,
+		Ratio:     0.5,
+		Labels:    map[string]string{"a": "b"},
+		Numbers:   []uint32{1, 2},
+		Timestamp: isoTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	expect, _ := json.Marshal(obj)
+
+	for _, c := range []Codec{JSON, PrettyJSON, YAML, MsgPack} {
+		data, err := c.Marshal(obj)
+		ut.Assert(t, err == nil, "marshal with %s failed %v", c.ContentType(), err)
+		jsonData, err := ToJSON(c, data)
+		ut.Assert(t, err == nil, "convert %s to json failed %v", c.ContentType(), err)
+
+		var decoded testObject
+		json.Unmarshal(jsonData, &decoded)
+		actual, _ := json.Marshal(decoded)
+		ut.Equal(t, string(actual), string(expect))
+
+		raw, err := c.Unmarshal(data)
+		ut.Assert(t, err == nil, "")
+		_, ok := raw.(map[string]interface{})
+		ut.Assert(t, ok, "%s should decode object into string map", c.ContentType())
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	ut.Equal(t, ForContentType("application/json; charset=utf-8"), JSON)
+	ut.Equal(t, ForContentType("Application/YAML"), YAML)
+	ut.Equal(t, ForContentType("application/vnd.msgpack"), MsgPack)
+	ut.Assert(t, ForContentType("text/plain") == nil, "")
+	ut.Assert(t, ForContentType(";;") == nil, "")
+}
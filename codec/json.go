@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"encoding/json"
+)
+
+type jsonCodec struct {
+	pretty bool
+}
+
+func (c *jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	if c.pretty {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
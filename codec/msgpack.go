@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{}
+
+func (c *msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (c *msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(g)
+}
+
+func (c *msgpackCodec) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := msgpack.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+type yamlCodec struct{}
+
+func (c *yamlCodec) ContentType() string {
+	return "application/yaml"
+}
+
+func (c *yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(g)
+}
+
+func (c *yamlCodec) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
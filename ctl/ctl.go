@@ -0,0 +1,276 @@
+package ctl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ben-han-cn/gorest/client"
+	"github.com/ben-han-cn/gorest/codec"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+const usage = `usage: gorestctl <command> <kind> [args] [flags]
+
+commands:
+  get <kind> <id>                  get one resource
+  list <kind>                      list resources in collection
+  create <kind> -f <file>          create resource from yaml or json file
+  update <kind> <id> -f <file>     update resource from yaml or json file
+  delete <kind> <id>               delete resource
+  action <kind> [id] <name>        run action of resource or collection, -f for input
+  kinds                            list resource kinds
+
+flags:
+  -o, --output table|json|yaml     output format, default is table
+  -f, --file <file>                file with resource or action input
+  -q, --query <query>              query of list, like "name=n1&sort=-name"
+  --<parent kind> <id>             id of parent resource, like --cluster c1
+`
+
+type Command struct {
+	client    *client.Client
+	version   resource.APIVersion
+	resources []resource.APIResource
+	out       io.Writer
+}
+
+//resources could be got by discovery or generated by the schema manager
+//compiled in
+func NewCommand(c *client.Client, resources *resource.APIResourceList, out io.Writer) *Command {
+	return &Command{
+		client: c,
+		version: resource.APIVersion{
+			Group:   resources.Group,
+			Version: resources.Version,
+		},
+		resources: resources.Resources,
+		out:       out,
+	}
+}
+
+func Discover(c *client.Client, version resource.APIVersion) (*resource.APIResourceList, error) {
+	var resources resource.APIResourceList
+	if err := c.GetLink(resource.ResourceLink(version.GetUrl()), &resources); err != nil {
+		return nil, err
+	}
+	return &resources, nil
+}
+
+func FormatError(err error) string {
+	if apiErr, ok := err.(*goresterr.APIError); ok {
+		return fmt.Sprintf("Error from server (%s, %d): %s", apiErr.Code, apiErr.Status, apiErr.Message)
+	}
+	return "Error: " + err.Error()
+}
+
+type options struct {
+	args    []string
+	output  string
+	file    string
+	query   string
+	parents map[string]string
+}
+
+func (c *Command) Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprint(c.out, usage)
+		return nil
+	}
+
+	cmd := args[0]
+	opts, err := c.parseOptions(args[1:])
+	if err != nil {
+		return err
+	}
+
+	if cmd == "kinds" {
+		return c.printKinds()
+	}
+
+	if len(opts.args) == 0 {
+		return fmt.Errorf("%s requires resource kind", cmd)
+	}
+	r, err := c.getResource(opts.args[0])
+	if err != nil {
+		return err
+	}
+	collectionPath, err := c.collectionPath(r, opts.parents)
+	if err != nil {
+		return err
+	}
+
+	args = opts.args[1:]
+	switch cmd {
+	case "get":
+		if len(args) != 1 {
+			return fmt.Errorf("get requires id of %s", r.Kind)
+		}
+		return c.request(http.MethodGet, resourcePath(collectionPath, args[0]), nil, opts.output)
+	case "list":
+		link := collectionPath
+		if opts.query != "" {
+			link = link + "?" + opts.query
+		}
+		return c.request(http.MethodGet, link, nil, opts.output)
+	case "create":
+		body, err := readFile(opts.file)
+		if err != nil {
+			return err
+		}
+		return c.request(http.MethodPost, collectionPath, body, opts.output)
+	case "update":
+		if len(args) != 1 {
+			return fmt.Errorf("update requires id of %s", r.Kind)
+		}
+		body, err := readFile(opts.file)
+		if err != nil {
+			return err
+		}
+		return c.request(http.MethodPut, resourcePath(collectionPath, args[0]), body, opts.output)
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("delete requires id of %s", r.Kind)
+		}
+		if err := c.client.Do(http.MethodDelete, resourcePath(collectionPath, args[0]), nil, nil); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "%s \"%s\" deleted\n", r.Kind, args[0])
+		return nil
+	case "action":
+		var link string
+		switch len(args) {
+		case 1:
+			link = collectionPath + "?action=" + url.QueryEscape(args[0])
+		case 2:
+			link = resourcePath(collectionPath, args[0]) + "?action=" + url.QueryEscape(args[1])
+		default:
+			return fmt.Errorf("action requires action name")
+		}
+		var body interface{}
+		if opts.file != "" {
+			if body, err = readFile(opts.file); err != nil {
+				return err
+			}
+		}
+		return c.request(http.MethodPost, link, body, opts.output)
+	default:
+		return fmt.Errorf("unknown command %s", cmd)
+	}
+}
+
+//flags may appear after the positional args like "get node n1 --cluster c1"
+func (c *Command) parseOptions(args []string) (*options, error) {
+	opts := &options{
+		output:  "table",
+		parents: make(map[string]string),
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") == false {
+			opts.args = append(opts.args, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		var value string
+		if j := strings.Index(name, "="); j != -1 {
+			name, value = name[:j], name[j+1:]
+		} else if i+1 < len(args) {
+			i += 1
+			value = args[i]
+		} else {
+			return nil, fmt.Errorf("flag %s has no value", arg)
+		}
+
+		switch name {
+		case "o", "output":
+			if value != "table" && value != "json" && value != "yaml" {
+				return nil, fmt.Errorf("unknown output format %s", value)
+			}
+			opts.output = value
+		case "f", "file":
+			opts.file = value
+		case "q", "query":
+			opts.query = value
+		default:
+			if _, err := c.getResource(name); err != nil {
+				return nil, fmt.Errorf("unknown flag %s", arg)
+			}
+			opts.parents[name] = value
+		}
+	}
+	return opts, nil
+}
+
+//kind could be kind name or resource name like node or nodes
+func (c *Command) getResource(kind string) (*resource.APIResource, error) {
+	for i, r := range c.resources {
+		if r.Kind == kind || r.Name == kind {
+			return &c.resources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown resource kind %s", kind)
+}
+
+//parent is selected by the parent flag if resource has several parents
+func (c *Command) collectionPath(r *resource.APIResource, parents map[string]string) (string, error) {
+	segments := []string{r.Name}
+	for len(r.Parents) > 0 {
+		var parent *resource.APIResource
+		for _, kind := range r.Parents {
+			if id, ok := parents[kind]; ok {
+				parent, _ = c.getResource(kind)
+				segments = append([]string{parent.Name, client.EscapeID(id)}, segments...)
+				break
+			}
+		}
+		if parent == nil {
+			return "", fmt.Errorf("%s requires parent flag --%s", r.Kind, strings.Join(r.Parents, " or --"))
+		}
+		r = parent
+	}
+	return c.version.GetUrl() + "/" + strings.Join(segments, "/"), nil
+}
+
+//id is escaped, so it can't escape from the collection
+func resourcePath(collectionPath, id string) string {
+	return collectionPath + "/" + client.EscapeID(id)
+}
+
+func (c *Command) request(method, link string, body interface{}, output string) error {
+	var result interface{}
+	if err := c.client.Do(method, link, body, &result); err != nil {
+		return err
+	}
+	return render(c.out, output, result)
+}
+
+func (c *Command) printKinds() error {
+	rows := make([][]string, 0, len(c.resources))
+	for _, r := range c.resources {
+		rows = append(rows, []string{r.Kind, r.Name, strings.Join(r.Parents, ","), strings.Join(r.Actions, ",")})
+	}
+	return printTable(c.out, []string{"KIND", "NAME", "PARENTS", "ACTIONS"}, rows)
+}
+
+//file is decoded as yaml which is superset of json
+func readFile(file string) (interface{}, error) {
+	if file == "" {
+		return nil, fmt.Errorf("file is required, specify it with -f")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v, err := codec.YAML.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s failed:%s", file, err.Error())
+	}
+	return v, nil
+}
@@ -0,0 +1,159 @@
+package ctl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest"
+	"github.com/ben-han-cn/gorest/client"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+var version = resource.APIVersion{
+	Group:   "testing",
+	Version: "v1",
+}
+
+type Cluster struct {
+	resource.ResourceBase
+	Name string `json:"name"`
+}
+
+type Node struct {
+	resource.ResourceBase
+	Address string `json:"address"`
+	Cpu     int    `json:"cpu"`
+}
+
+func (n Node) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+type clusterHandler struct{}
+
+func (h *clusterHandler) List(ctx *resource.Context) interface{} {
+	c := &Cluster{Name: "c1"}
+	c.SetID("c1")
+	return []*Cluster{c}
+}
+
+type nodeHandler struct {
+	nodes []*Node
+}
+
+func (h *nodeHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	n := ctx.Resource.(*Node)
+	n.SetID(n.Address)
+	h.nodes = append(h.nodes, n)
+	return n, nil
+}
+
+func (h *nodeHandler) List(ctx *resource.Context) interface{} {
+	return h.nodes
+}
+
+func (h *nodeHandler) Get(ctx *resource.Context) resource.Resource {
+	for _, n := range h.nodes {
+		if n.GetID() == ctx.Resource.GetID() {
+			return n
+		}
+	}
+	return nil
+}
+
+func (h *nodeHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	for i, n := range h.nodes {
+		if n.GetID() == ctx.Resource.GetID() {
+			h.nodes = append(h.nodes[:i], h.nodes[i+1:]...)
+			return nil
+		}
+	}
+	return goresterr.NewAPIError(goresterr.NotFound, "node doesn't exist")
+}
+
+func TestCommand(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(&version, Node{}, &nodeHandler{})
+	server := httptest.NewServer(gorest.NewAPIServer(mgr))
+	defer server.Close()
+
+	c, _ := client.New(server.URL, version)
+	resources, err := Discover(c, version)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, resources, mgr.GetAPIResourceList(&version))
+
+	var out bytes.Buffer
+	cmd := NewCommand(c, resources, &out)
+	run := func(args ...string) (string, error) {
+		out.Reset()
+		err := cmd.Run(args)
+		return out.String(), err
+	}
+
+	dir, _ := ioutil.TempDir("", "gorestctl")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "node.yaml")
+	ioutil.WriteFile(file, []byte("address: 1.1.1.1\ncpu: 4\n"), 0644)
+
+	output, err := run("create", "node", "-f", file, "--cluster", "c1")
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, output, "ID        ADDRESS   CPU\n1.1.1.1   1.1.1.1   4\n")
+
+	output, err = run("get", "node", "1.1.1.1", "--cluster=c1", "-o", "yaml")
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, strings.Contains(output, "address: 1.1.1.1\n"), "")
+
+	output, err = run("list", "nodes", "--cluster", "c1", "-q", "sort=-cpu")
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, strings.Count(output, "\n"), 2)
+
+	output, err = run("list", "cluster", "-o", "json")
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, strings.Contains(output, "\"name\": \"c1\""), "")
+
+	_, err = run("get", "node", "1.1.1.1")
+	ut.Equal(t, err.Error(), "node requires parent flag --cluster")
+
+	output, err = run("delete", "node", "1.1.1.1", "--cluster", "c1")
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, output, "node \"1.1.1.1\" deleted\n")
+
+	_, err = run("get", "node", "1.1.1.1", "--cluster", "c1")
+	ut.Equal(t, FormatError(err), "Error from server (NotFound, 404): node resource with id 1.1.1.1 doesn't exist")
+
+	_, err = run("get", "pod", "p1")
+	ut.Equal(t, err.Error(), "unknown resource kind pod")
+}
+
+func TestEscapeID(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(&version, Node{}, &nodeHandler{})
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	c, _ := client.New(server.URL, version)
+	cmd := NewCommand(c, mgr.GetAPIResourceList(&version), ioutil.Discard)
+	ut.Assert(t, cmd.Run([]string{"get", "node", "../x", "--cluster", "c/1"}) == nil, "")
+	ut.Assert(t, cmd.Run([]string{"delete", "node", "..", "--cluster", "c1"}) == nil, "")
+	ut.Assert(t, cmd.Run([]string{"action", "node", "a?b", "reboot", "--cluster", "c1"}) == nil, "")
+	ut.Equal(t, paths, []string{
+		"/apis/testing/v1/clusters/c%2F1/nodes/..%2Fx",
+		"/apis/testing/v1/clusters/c1/nodes/%2E%2E",
+		"/apis/testing/v1/clusters/c1/nodes/a%3Fb",
+	})
+}
@@ -0,0 +1,112 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ben-han-cn/gorest/codec"
+)
+
+//common fields which aren't shown in table
+var hiddenFields = []string{"id", "type", "links", "resourceVersion", "creationTimestamp", "deletionTimestamp"}
+
+func render(out io.Writer, output string, result interface{}) error {
+	switch output {
+	case "json":
+		return write(out, codec.PrettyJSON, result)
+	case "yaml":
+		return write(out, codec.YAML, result)
+	}
+
+	obj, ok := result.(map[string]interface{})
+	if ok == false {
+		return write(out, codec.YAML, result)
+	}
+
+	//collection has data, resource has id, others like action
+	//result are shown as yaml
+	if data, ok := obj["data"].([]interface{}); ok && obj["type"] == "collection" {
+		var objs []map[string]interface{}
+		for _, e := range data {
+			if o, ok := e.(map[string]interface{}); ok {
+				objs = append(objs, o)
+			}
+		}
+		return printResources(out, objs)
+	} else if _, ok := obj["id"]; ok {
+		return printResources(out, []map[string]interface{}{obj})
+	}
+	return write(out, codec.YAML, result)
+}
+
+func write(out io.Writer, c codec.Codec, result interface{}) error {
+	data, err := c.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func printResources(out io.Writer, objs []map[string]interface{}) error {
+	columns := make(map[string]bool)
+	for _, obj := range objs {
+		for k := range obj {
+			columns[k] = true
+		}
+	}
+	for _, k := range hiddenFields {
+		delete(columns, k)
+	}
+
+	names := make([]string, 0, len(columns))
+	for k := range columns {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	header := []string{"ID"}
+	for _, name := range names {
+		header = append(header, strings.ToUpper(name))
+	}
+	rows := make([][]string, 0, len(objs))
+	for _, obj := range objs {
+		row := []string{cellValue(obj["id"])}
+		for _, name := range names {
+			row = append(row, cellValue(obj[name]))
+		}
+		rows = append(rows, row)
+	}
+	return printTable(out, header, rows)
+}
+
+//struct, slice and map are shown as compact json
+func cellValue(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func printTable(out io.Writer, header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
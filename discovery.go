@@ -0,0 +1,61 @@
+package gorest
+
+import (
+	"net/http"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+const OpenAPIPath = resource.GroupPrefix + "/openapi"
+
+//return the handler of discovery documents, which runs after the server
+//middlewares like resource handler, but Resource in context is nil, nil
+//is returned if the request isn't for discovery
+func (s *Server) discoveryHandler(req *http.Request) HandlerFunc {
+	if req.Method != http.MethodGet {
+		return nil
+	}
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if path == resource.GroupPrefix {
+		return documentHandler(s.Schemas.GetAPIVersionList())
+	}
+
+	if path == OpenAPIPath && s.openAPI {
+		return func(ctx *resource.Context) *goresterr.APIError {
+			ctx.SetResponse(http.StatusOK, s.Schemas.GenerateOpenAPI())
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(path, resource.GroupPrefix+"/") == false {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(path, resource.GroupPrefix+"/"), "/")
+	if len(segments) != 2 && (len(segments) != 4 || segments[2] != resource.SchemasPath) {
+		return nil
+	}
+
+	//unknown version or kind is reported when create context
+	version := &resource.APIVersion{
+		Group:   segments[0],
+		Version: segments[1],
+	}
+	if len(segments) == 2 {
+		if resources := s.Schemas.GetAPIResourceList(version); resources != nil {
+			return documentHandler(resources)
+		}
+	} else if schema := s.Schemas.GetAPIResourceSchema(version, segments[3]); schema != nil {
+		return documentHandler(schema)
+	}
+	return nil
+}
+
+func documentHandler(doc interface{}) HandlerFunc {
+	return func(ctx *resource.Context) *goresterr.APIError {
+		ctx.SetResponse(http.StatusOK, doc)
+		return nil
+	}
+}
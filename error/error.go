@@ -5,8 +5,11 @@ var (
 	PermissionDenied = ErrorCode{"PermissionDenied", 403}
 	NotFound         = ErrorCode{"NotFound", 404}
 	MethodNotAllowed = ErrorCode{"MethodNotAllow", 405}
+	NotAcceptable    = ErrorCode{"NotAcceptable", 406}
 	Conflict         = ErrorCode{"Conflict", 409}
 
+	UnsupportedMediaType = ErrorCode{"UnsupportedMediaType", 415}
+
 	DuplicateResource  = ErrorCode{"DuplicateResource", 422}
 	DeleteParent       = ErrorCode{"DeleteParent", 422}
 	InvalidFormat      = ErrorCode{"InvalidFormat", 422}
@@ -23,11 +26,23 @@ var (
 	InvalidAction      = ErrorCode{"InvalidAction", 422}
 	InvalidBodyContent = ErrorCode{"InvalidBodyContent", 422}
 	InvalidType        = ErrorCode{"InvalidType", 422}
+	UnknownField       = ErrorCode{"UnknownField", 422}
 
 	ServerError        = ErrorCode{"ServerError", 500}
 	ClusterUnavailable = ErrorCode{"ClusterUnavailable", 503}
 )
 
+//all the error codes, used to generate api document
+var ErrorCodes = []ErrorCode{
+	Unauthorized, PermissionDenied, NotFound, MethodNotAllowed, NotAcceptable, Conflict,
+	UnsupportedMediaType,
+	DuplicateResource, DeleteParent, InvalidFormat, NotNullable, NotUnique,
+	MinLimitExceeded, MaxLimitExceeded, MinLengthExceeded, MaxLengthExceeded,
+	InvalidOption, InvalidCharacters, MissingRequired, InvalidCSRFToken,
+	InvalidAction, InvalidBodyContent, InvalidType, UnknownField,
+	ServerError, ClusterUnavailable,
+}
+
 type ErrorCode struct {
 	Code   string `json:"code,omitempty"`
 	Status int    `json:"status,omitempty"`
@@ -35,8 +50,18 @@ type ErrorCode struct {
 
 type APIError struct {
 	ErrorCode `json:",inline"`
-	Type      string `json:"type,omitempty"`
-	Message   string `json:"message,omitempty"`
+	Type      string        `json:"type,omitempty"`
+	Message   string        `json:"message,omitempty"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+}
+
+//cause of error on a field, field is json pointer like /nodes/2/address,
+//params are the parameters of the rule like {"minLen":1, "maxLen":10}
+type ErrorDetail struct {
+	Code    string                 `json:"code"`
+	Field   string                 `json:"field"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Message string                 `json:"message"`
 }
 
 func NewAPIError(code ErrorCode, message string) *APIError {
@@ -47,6 +72,12 @@ func NewAPIError(code ErrorCode, message string) *APIError {
 	}
 }
 
+func NewAPIErrorWithDetails(code ErrorCode, message string, details []ErrorDetail) *APIError {
+	err := NewAPIError(code, message)
+	err.Details = details
+	return err
+}
+
 func (e *APIError) Error() string {
 	return e.Message
 }
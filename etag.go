@@ -0,0 +1,117 @@
+package gorest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
+)
+
+const (
+	ETagKey        = "ETag"
+	IfMatchKey     = "If-Match"
+	IfNoneMatchKey = "If-None-Match"
+)
+
+func resourceETag(r resource.Resource) string {
+	if version := r.GetResourceVersion(); version != "" {
+		return "\"" + version + "\""
+	}
+	return ""
+}
+
+func setETag(ctx *resource.Context, r resource.Resource) {
+	if etag := resourceETag(r); etag != "" {
+		ctx.Response.Header().Set(ETagKey, etag)
+	}
+}
+
+type etagComparison int
+
+const (
+	//used by If-Match, weak etag never matches
+	strongComparison etagComparison = iota
+	//used by If-None-Match, weak etag matches the same version
+	weakComparison
+)
+
+//header is a list of etags separated by comma, "*" matches any existing
+//resource, etag of resource is always strong, rfc7232 section 2.3.2
+func etagMatch(header, etag string, comparison etagComparison) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if comparison == weakComparison {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if etag != "" && tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func getCurrentResource(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	handler := ctx.Resource.GetSchema().GetHandler().GetGetHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler to get the current resource")
+	}
+
+	end := startSpan(ctx, trace.HandlerSpan)
+	r := handler(ctx)
+	end(nil)
+	if r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil()) {
+		return nil, goresterr.NewAPIError(goresterr.NotFound,
+			fmt.Sprintf("%s resource with id %s doesn't exist", ctx.Resource.GetType(), ctx.Resource.GetID()))
+	}
+	return r, nil
+}
+
+//for update, resource version in body has the same effect with If-Match
+func needCheckPrecondition(ctx *resource.Context) bool {
+	return ctx.Request.Header.Get(IfMatchKey) != "" ||
+		(ctx.Method == http.MethodPut && ctx.Resource.GetResourceVersion() != "")
+}
+
+//check If-Match against current resource before it's modified, the version
+//of current resource is set to ctx.Resource, so handler could make sure
+//the resource isn't changed in the meantime
+func checkPrecondition(ctx *resource.Context, current resource.Resource) *goresterr.APIError {
+	if ifMatch := ctx.Request.Header.Get(IfMatchKey); ifMatch != "" {
+		if etagMatch(ifMatch, resourceETag(current), strongComparison) == false {
+			return goresterr.NewAPIError(goresterr.Conflict,
+				fmt.Sprintf("%s resource with id %s has been modified", ctx.Resource.GetType(), ctx.Resource.GetID()))
+		}
+	}
+
+	return checkResourceVersion(ctx.Resource, current)
+}
+
+//empty version is set to the version of current resource, otherwise they
+//should be same, used for resource in body of update and patched resource
+func checkResourceVersion(r, current resource.Resource) *goresterr.APIError {
+	if version := r.GetResourceVersion(); version == "" {
+		r.SetResourceVersion(current.GetResourceVersion())
+	} else if version != current.GetResourceVersion() {
+		return goresterr.NewAPIError(goresterr.Conflict,
+			fmt.Sprintf("%s resource with id %s has been modified", r.GetType(), r.GetID()))
+	}
+	return nil
+}
+
+//return true if 304 is returned
+func checkNotModified(ctx *resource.Context, r resource.Resource) bool {
+	ifNoneMatch := ctx.Request.Header.Get(IfNoneMatchKey)
+	if ifNoneMatch == "" || etagMatch(ifNoneMatch, resourceETag(r), weakComparison) == false {
+		return false
+	}
+
+	ctx.SetResponse(http.StatusNotModified, nil)
+	return true
+}
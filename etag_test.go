@@ -0,0 +1,130 @@
+package gorest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+type versionedBarHandler struct {
+	bar     *Bar
+	version int
+}
+
+func (h *versionedBarHandler) Get(ctx *resource.Context) resource.Resource {
+	if h.bar == nil || ctx.Resource.GetID() != h.bar.GetID() {
+		return nil
+	}
+	return h.bar
+}
+
+func (h *versionedBarHandler) save(bar *Bar) {
+	h.version += 1
+	bar.SetResourceVersion(strconv.Itoa(h.version))
+	h.bar = bar
+}
+
+func (h *versionedBarHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.save(ctx.Resource.(*Bar))
+	return h.bar, nil
+}
+
+func (h *versionedBarHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.save(ctx.Resource.(*Bar))
+	return h.bar, nil
+}
+
+func (h *versionedBarHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	h.bar = nil
+	return nil
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	handler := &versionedBarHandler{}
+	handler.save(bar)
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+
+	serve := func(method, body string, header map[string]string) *httptest.ResponseRecorder {
+		var req *http.Request
+		if body == "" {
+			req, _ = http.NewRequest(method, "/apis/testing/v1/bars/b1", nil)
+		} else {
+			req, _ = http.NewRequest(method, "/apis/testing/v1/bars/b1", bytes.NewBufferString(body))
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "", nil)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get(ETagKey), `"1"`)
+
+	w = serve(http.MethodGet, "", map[string]string{IfNoneMatchKey: `"0", "1"`})
+	ut.Equal(t, w.Code, http.StatusNotModified)
+	ut.Equal(t, w.Body.Len(), 0)
+
+	w = serve(http.MethodGet, "", map[string]string{IfNoneMatchKey: `"0"`})
+	ut.Equal(t, w.Code, http.StatusOK)
+
+	//weak comparison for If-None-Match, strong for If-Match
+	w = serve(http.MethodGet, "", map[string]string{IfNoneMatchKey: `W/"1"`})
+	ut.Equal(t, w.Code, http.StatusNotModified)
+	w = serve(http.MethodPut, `{"name":"b2","count":3}`, map[string]string{IfMatchKey: `W/"1"`})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+
+	w = serve(http.MethodPut, `{"name":"b2","count":3}`, map[string]string{IfMatchKey: `"1"`})
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get(ETagKey), `"2"`)
+	ut.Equal(t, handler.bar.Name, "b2")
+
+	//stale version in header and body
+	w = serve(http.MethodPut, `{"name":"b3","count":3}`, map[string]string{IfMatchKey: `"1"`})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	w = serve(http.MethodPut, `{"name":"b3","count":3,"resourceVersion":"1"}`, nil)
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	ut.Equal(t, handler.bar.Name, "b2")
+
+	w = serve(http.MethodPut, `{"name":"b3","count":3,"resourceVersion":"2"}`, nil)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.bar.Name, "b3")
+
+	w = serve(http.MethodPatch, `{"count":4}`, map[string]string{ContentTypeKey: resource.MergePatchType, IfMatchKey: `W/"2"`})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	w = serve(http.MethodPatch, `{"count":4}`, map[string]string{ContentTypeKey: resource.MergePatchType, IfMatchKey: `"3"`})
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.bar.Count, 4)
+
+	//resource version in patch is checked like update
+	w = serve(http.MethodPatch, `{"count":5,"resourceVersion":"1"}`, map[string]string{ContentTypeKey: resource.MergePatchType})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	w = serve(http.MethodPatch, `[{"op":"replace","path":"/resourceVersion","value":"9"}]`, map[string]string{ContentTypeKey: resource.JSONPatchType})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	ut.Equal(t, handler.bar.Count, 4)
+	w = serve(http.MethodPatch, `{"count":5,"resourceVersion":"4"}`, map[string]string{ContentTypeKey: resource.MergePatchType})
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.bar.Count, 5)
+
+	w = serve(http.MethodDelete, "", map[string]string{IfMatchKey: `"4"`})
+	ut.Equal(t, w.Code, goresterr.Conflict.Status)
+	w = serve(http.MethodDelete, "", map[string]string{IfMatchKey: "*"})
+	ut.Equal(t, w.Code, http.StatusNoContent)
+	ut.Assert(t, handler.bar == nil, "")
+
+	w = serve(http.MethodDelete, "", map[string]string{IfMatchKey: "*"})
+	ut.Equal(t, w.Code, http.StatusNotFound)
+}
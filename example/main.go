@@ -39,21 +39,23 @@ type Node struct {
 	IsWorker              bool   `json:"isWorker"`
 }
 
+//names returned by GetActionNames must be the ones CreateAction accepts
+var clusterActions = []string{"encode", "decode"}
+
 func (c Cluster) CreateAction(name string) *resource.Action {
-	switch name {
-	case "encode":
-		return &resource.Action{
-			Name:  "encode",
-			Input: &Input{},
+	for _, action := range clusterActions {
+		if action == name {
+			return &resource.Action{
+				Name:  name,
+				Input: &Input{},
+			}
 		}
-	case "decode":
-		return &resource.Action{
-			Name:  "decode",
-			Input: &Input{},
-		}
-	default:
-		return nil
 	}
+	return nil
+}
+
+func (c Cluster) GetActionNames() []string {
+	return clusterActions
 }
 
 type Input struct {
@@ -0,0 +1,94 @@
+package gorest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/metrics"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+const MetricsPath = "/metrics"
+
+//resource id isn't used as label to keep the number of series bounded,
+//action requests have verb action and the action name as label
+var requestLabels = []string{"group", "version", "kind", "verb", "action"}
+
+type serverMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.CounterVec
+	errors   *metrics.CounterVec
+	latency  *metrics.HistogramVec
+	inFlight *metrics.GaugeVec
+}
+
+func newServerMetrics() *serverMetrics {
+	registry := metrics.NewRegistry()
+	return &serverMetrics{
+		registry: registry,
+		requests: registry.NewCounterVec("gorest_requests_total",
+			"number of requests by resource, verb and response status",
+			append(requestLabels, "status")...),
+		errors: registry.NewCounterVec("gorest_request_errors_total",
+			"number of requests failed with api error",
+			append(requestLabels, "code")...),
+		latency: registry.NewHistogramVec("gorest_request_duration_seconds",
+			"latency of requests, watch lasts until it's closed",
+			metrics.DefaultBuckets, requestLabels...),
+		inFlight: registry.NewGaugeVec("gorest_requests_in_flight",
+			"number of requests being served",
+			requestLabels...),
+	}
+}
+
+//return nil if the request isn't for metrics, the handler writes response
+//by itself, since metrics isn't encoded by codec
+func (s *Server) metricsHandler(req *http.Request) HandlerFunc {
+	if s.metrics == nil || req.Method != http.MethodGet || req.URL.Path != MetricsPath {
+		return nil
+	}
+	return func(ctx *resource.Context) *goresterr.APIError {
+		s.metrics.registry.ServeHTTP(ctx.Response, ctx.Request)
+		return nil
+	}
+}
+
+func requestLabelValues(ctx *resource.Context) []string {
+	var group, version string
+	if v := ctx.Resource.GetSchema().GetAPIVersion(); v != nil {
+		group, version = v.Group, v.Version
+	}
+
+	verb, action := ctx.GetVerb(), ""
+	if a := ctx.Resource.GetAction(); a != nil {
+		verb, action = "action", a.Name
+	}
+	return []string{group, version, ctx.Resource.GetType(), verb, action}
+}
+
+//return the function to call when the request is done
+func (m *serverMetrics) begin(ctx *resource.Context) func(*goresterr.APIError) {
+	labels := requestLabelValues(ctx)
+	start := time.Now()
+	m.inFlight.Inc(labels...)
+	return func(err *goresterr.APIError) {
+		m.inFlight.Dec(labels...)
+		m.latency.Observe(time.Since(start).Seconds(), labels...)
+		status, _ := ctx.GetResponse()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.observe(labels, status, err)
+	}
+}
+
+//request which fails before context is created has no resource labels
+func (m *serverMetrics) observe(labels []string, status int, err *goresterr.APIError) {
+	if err != nil {
+		status = err.Status
+		m.errors.Inc(append(labels, err.Code)...)
+	}
+	m.requests.Inc(append(labels, strconv.Itoa(status))...)
+}
@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metricType string
+
+const (
+	counterType   metricType = "counter"
+	gaugeType     metricType = "gauge"
+	histogramType metricType = "histogram"
+)
+
+//registry of metrics which are exposed in prometheus text format, metric
+//has fixed label names, series is created when it's first updated
+type Registry struct {
+	lock    sync.Mutex
+	metrics []*metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+type metric struct {
+	name    string
+	help    string
+	typ     metricType
+	labels  []string
+	buckets []float64
+	lock    sync.Mutex
+	series  map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	//for histogram, counts[i] is the count of observations in bucket i
+	counts []uint64
+	count  uint64
+}
+
+func (r *Registry) register(m *metric) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, old := range r.metrics {
+		if old.name == m.name {
+			panic(fmt.Sprintf("duplicate metric %s", m.name))
+		}
+	}
+	m.series = make(map[string]*series)
+	r.metrics = append(r.metrics, m)
+}
+
+func (m *metric) getSeries(labelValues []string) *series {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels but %d values are provided", m.name, len(m.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if ok == false {
+		s = &series{
+			labelValues: append([]string{}, labelValues...),
+		}
+		if m.typ == histogramType {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+type CounterVec struct {
+	m *metric
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	m := &metric{name: name, help: help, typ: counterType, labels: labels}
+	r.register(m)
+	return &CounterVec{m}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("counter cannot decrease")
+	}
+	c.m.lock.Lock()
+	c.m.getSeries(labelValues).value += v
+	c.m.lock.Unlock()
+}
+
+type GaugeVec struct {
+	m *metric
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	m := &metric{name: name, help: help, typ: gaugeType, labels: labels}
+	r.register(m)
+	return &GaugeVec{m}
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.m.lock.Lock()
+	g.m.getSeries(labelValues).value = v
+	g.m.lock.Unlock()
+}
+
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	g.m.lock.Lock()
+	g.m.getSeries(labelValues).value += v
+	g.m.lock.Unlock()
+}
+
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+type HistogramVec struct {
+	m *metric
+}
+
+//buckets are upper bounds in increasing order, +Inf bucket is implicit
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if sort.Float64sAreSorted(buckets) == false {
+		panic(fmt.Sprintf("buckets of histogram %s aren't sorted", name))
+	}
+	m := &metric{name: name, help: help, typ: histogramType, labels: labels, buckets: buckets}
+	r.register(m)
+	return &HistogramVec{m}
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.m.lock.Lock()
+	defer h.m.lock.Unlock()
+	s := h.m.getSeries(labelValues)
+	if i := sort.SearchFloat64s(h.m.buckets, v); i < len(h.m.buckets) {
+		s.counts[i] += 1
+	}
+	s.count += 1
+	s.value += v
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lock.Lock()
+	metrics := append([]*metric{}, r.metrics...)
+	r.lock.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+func (m *metric) writeText(w *bufio.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.typ)
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.series[key]
+		if m.typ != histogramType {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, formatLabels(m.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteText(w)
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var pairs []string
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(values[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extraName, extraValue))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpReplacer.Replace(v)
+}
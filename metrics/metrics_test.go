@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("requests_total", "number of requests", "kind")
+	g := r.NewGaugeVec("in_flight", "requests in flight")
+	h := r.NewHistogramVec("latency_seconds", "latency", []float64{0.1, 1}, "kind")
+
+	c.Inc("node")
+	c.Add(2, "node")
+	c.Inc(`a"b`)
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	h.Observe(0.05, "node")
+	h.Observe(0.5, "node")
+	h.Observe(5, "node")
+
+	var buf bytes.Buffer
+	ut.Assert(t, r.WriteText(&buf) == nil, "")
+	ut.Equal(t, buf.String(), `# HELP requests_total number of requests
+# TYPE requests_total counter
+requests_total{kind="a\"b"} 1
+requests_total{kind="node"} 3
+# HELP in_flight requests in flight
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{kind="node",le="0.1"} 1
+latency_seconds_bucket{kind="node",le="1"} 2
+latency_seconds_bucket{kind="node",le="+Inf"} 3
+latency_seconds_sum{kind="node"} 5.55
+latency_seconds_count{kind="node"} 3
+`)
+}
@@ -0,0 +1,220 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ben-han-cn/cement/slice"
+	"github.com/ben-han-cn/gorest/auth"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+//what the request wants to do
+type Attributes struct {
+	APIGroup string
+	Version  string
+	Kind     string
+	//kind and id of ancestors from the toplevel one
+	Parents []string
+	ID      string
+	Verb    string
+	//path of request which isn't on resource like discovery and metrics,
+	//empty for resource request
+	Path string
+}
+
+func NewAttributes(ctx *resource.Context) Attributes {
+	r := ctx.Resource
+	if r == nil {
+		path := strings.TrimSuffix(ctx.Request.URL.Path, "/")
+		if path == "" {
+			path = "/"
+		}
+		return Attributes{
+			Path: path,
+			Verb: ctx.GetVerb(),
+		}
+	}
+
+	var parents []string
+	for _, ancestor := range resource.GetAncestors(r) {
+		parents = append(parents, ancestor.GetType(), ancestor.GetID())
+	}
+
+	attr := Attributes{
+		Kind:    r.GetType(),
+		Parents: parents,
+		ID:      r.GetID(),
+		Verb:    ctx.GetVerb(),
+	}
+	if version := r.GetSchema().GetAPIVersion(); version != nil {
+		attr.APIGroup = version.Group
+		attr.Version = version.Version
+	}
+	return attr
+}
+
+type Authorizer struct {
+	policy *Policy
+}
+
+func NewAuthorizer(policy *Policy) (*Authorizer, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &Authorizer{
+		policy: policy,
+	}, nil
+}
+
+func (a *Authorizer) Authorize(user *auth.User, attr Attributes) bool {
+	for _, rule := range a.matchRules(user, attr) {
+		if len(rule.IDs) == 0 || slice.SliceIndex(rule.IDs, attr.ID) != -1 {
+			return true
+		}
+	}
+	return false
+}
+
+//authenticated user is required, so the middleware should run after the
+//authentication middleware, resources in list and watch are filtered by
+//the ids in rules
+func (a *Authorizer) Middleware() resource.Middleware {
+	return func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		user := auth.GetUser(ctx)
+		if user == nil {
+			return goresterr.NewAPIError(goresterr.PermissionDenied, "anonymous user has no permission")
+		}
+
+		attr := NewAttributes(ctx)
+		if attr.Verb != VerbList {
+			if a.Authorize(user, attr) == false {
+				return permissionDenied(user, attr)
+			}
+			return next()
+		}
+
+		rules := a.matchRules(user, attr)
+		if len(rules) == 0 {
+			return permissionDenied(user, attr)
+		}
+		ctx.AddResourcePredicate(func(r resource.Resource) bool {
+			for _, rule := range rules {
+				if len(rule.IDs) == 0 || slice.SliceIndex(rule.IDs, r.GetID()) != -1 {
+					return true
+				}
+			}
+			return false
+		})
+		return next()
+	}
+}
+
+func permissionDenied(user *auth.User, attr Attributes) *goresterr.APIError {
+	target := attr.Kind
+	if attr.Path != "" {
+		target = attr.Path
+	} else if attr.ID != "" {
+		target = fmt.Sprintf("%s %s", attr.Kind, attr.ID)
+	}
+	return goresterr.NewAPIError(goresterr.PermissionDenied,
+		fmt.Sprintf("user %s isn't allowed to %s %s", user.Name, attr.Verb, target))
+}
+
+//rules bound to the user which match the attributes except id
+func (a *Authorizer) matchRules(user *auth.User, attr Attributes) []*Rule {
+	var rules []*Rule
+	for _, binding := range a.policy.Bindings {
+		if isBound(binding, user) == false {
+			continue
+		}
+		for i := range a.policy.Roles {
+			role := &a.policy.Roles[i]
+			if role.Name != binding.Role {
+				continue
+			}
+			for j := range role.Rules {
+				if rule := &role.Rules[j]; rule.match(attr) {
+					rules = append(rules, rule)
+				}
+			}
+		}
+	}
+	return rules
+}
+
+func isBound(binding Binding, user *auth.User) bool {
+	if slice.SliceIndex(binding.Users, Wildcard) != -1 || slice.SliceIndex(binding.Users, user.Name) != -1 {
+		return true
+	}
+	for _, group := range user.Groups {
+		if slice.SliceIndex(binding.Groups, group) != -1 {
+			return true
+		}
+	}
+	return false
+}
+
+//rule with non resource paths only matches the requests which aren't on
+//resource, and the other rules only match resource requests
+func (rule *Rule) match(attr Attributes) bool {
+	if len(rule.NonResourcePaths) != 0 || attr.Path != "" {
+		return attr.Path != "" &&
+			matchPath(rule.NonResourcePaths, attr.Path) &&
+			matchVerb(rule.Verbs, attr.Verb)
+	}
+
+	return matchAny(rule.APIGroups, attr.APIGroup) &&
+		matchAny(rule.Versions, attr.Version) &&
+		matchAny(rule.Kinds, attr.Kind) &&
+		matchParents(rule.Parents, attr.Parents) &&
+		matchVerb(rule.Verbs, attr.Verb)
+}
+
+func matchAny(patterns []string, value string) bool {
+	return len(patterns) == 0 || slice.SliceIndex(patterns, Wildcard) != -1 || slice.SliceIndex(patterns, value) != -1
+}
+
+func matchPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if pattern == path || pattern == Wildcard {
+			return true
+		}
+		if strings.HasSuffix(pattern, Wildcard) && strings.HasPrefix(path, strings.TrimSuffix(pattern, Wildcard)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchParents(patterns []string, parents []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		segments := strings.Split(pattern, "/")
+		if len(segments) > len(parents) {
+			continue
+		}
+		matched := true
+		for i, seg := range segments {
+			if seg != Wildcard && seg != parents[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchVerb(verbs []string, verb string) bool {
+	if slice.SliceIndex(verbs, Wildcard) != -1 || slice.SliceIndex(verbs, verb) != -1 {
+		return true
+	}
+	return strings.HasPrefix(verb, resource.ActionVerbPrefix) && slice.SliceIndex(verbs, AnyActionVerb) != -1
+}
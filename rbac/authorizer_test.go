@@ -0,0 +1,195 @@
+package rbac
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest"
+	"github.com/ben-han-cn/gorest/auth"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+type Cluster struct {
+	resource.ResourceBase
+}
+
+func (c Cluster) CreateAction(name string) *resource.Action {
+	if name == "upgrade" {
+		return &resource.Action{Name: name}
+	}
+	return nil
+}
+
+type Node struct {
+	resource.ResourceBase
+}
+
+func (n Node) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+type clusterHandler struct{}
+
+func (h *clusterHandler) List(ctx *resource.Context) interface{} {
+	var clusters []*Cluster
+	for _, id := range []string{"c1", "c2"} {
+		c := &Cluster{}
+		c.SetID(id)
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+func (h *clusterHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	return nil, nil
+}
+
+type nodeHandler struct{}
+
+func (h *nodeHandler) Get(ctx *resource.Context) resource.Resource {
+	n := &Node{}
+	n.SetID(ctx.Resource.GetID())
+	return n
+}
+
+func (h *nodeHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	return nil
+}
+
+const testPolicy = `
+roles:
+- name: viewer
+  rules:
+  - verbs: [get, list]
+    ids: [c1]
+    kinds: [cluster]
+  - verbs: [get, list]
+    kinds: [node]
+- name: ops
+  rules:
+  - apiGroups: [testing]
+    kinds: [node]
+    parents: [cluster/c1]
+    verbs: [delete]
+  - kinds: [cluster]
+    verbs: ["action:*"]
+  - nonResourcePaths: [/apis*]
+    verbs: [get]
+bindings:
+- role: viewer
+  users: ["*"]
+- role: ops
+  groups: [ops]
+`
+
+func newTestServer(t *testing.T) *gorest.Server {
+	dir, _ := ioutil.TempDir("", "rbac")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "policy.yaml")
+	ioutil.WriteFile(file, []byte(testPolicy), 0644)
+	policy, err := LoadPolicy(file)
+	ut.Assert(t, err == nil, "load policy failed:%v", err)
+	authorizer, err := NewAuthorizer(policy)
+	ut.Assert(t, err == nil, "")
+
+	version := &resource.APIVersion{Group: "testing", Version: "v1"}
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(version, Node{}, &nodeHandler{})
+	s := gorest.NewAPIServer(mgr)
+	s.UseMiddleware(auth.Middleware(auth.NewBasicAuthenticator(auth.DefaultRealm, map[string]auth.BasicCredential{
+		"ben": auth.BasicCredential{Password: "ben", Groups: []string{"ops"}},
+		"joe": auth.BasicCredential{Password: "joe"},
+	})))
+	s.UseMiddleware(authorizer.Middleware())
+	return s
+}
+
+func doRequest(s *gorest.Server, user, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.SetBasicAuth(user, user)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthorizer(t *testing.T) {
+	s := newTestServer(t)
+
+	w := doRequest(s, "joe", http.MethodGet, "/apis/testing/v1/clusters")
+	ut.Equal(t, w.Code, http.StatusOK)
+	var clusters struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &clusters)
+	ut.Equal(t, len(clusters.Data), 1)
+	ut.Equal(t, clusters.Data[0]["id"], "c1")
+
+	w = doRequest(s, "joe", http.MethodGet, "/apis/testing/v1/clusters/c1/nodes/n1")
+	ut.Equal(t, w.Code, http.StatusOK)
+
+	for _, path := range []string{
+		"/apis/testing/v1/clusters/c1/nodes/n1",
+		"/apis/testing/v1/clusters/c2/nodes/n1",
+	} {
+		w = doRequest(s, "joe", http.MethodDelete, path)
+		ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+	}
+
+	w = doRequest(s, "ben", http.MethodDelete, "/apis/testing/v1/clusters/c1/nodes/n1")
+	ut.Equal(t, w.Code, http.StatusNoContent)
+	w = doRequest(s, "ben", http.MethodDelete, "/apis/testing/v1/clusters/c2/nodes/n1")
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+
+	w = doRequest(s, "ben", http.MethodPost, "/apis/testing/v1/clusters/c2?action=upgrade")
+	ut.Equal(t, w.Code, http.StatusOK)
+	w = doRequest(s, "joe", http.MethodPost, "/apis/testing/v1/clusters/c2?action=upgrade")
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+}
+
+func TestAuthorizeNonResource(t *testing.T) {
+	s := newTestServer(t)
+
+	//resource rule which matches any kind doesn't apply to discovery
+	w := doRequest(s, "joe", http.MethodGet, "/apis")
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+	w = doRequest(s, "joe", http.MethodGet, "/apis/testing/v1/schemas/cluster")
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+
+	for _, path := range []string{
+		"/apis",
+		"/apis/",
+		"/apis/testing/v1",
+		"/apis/testing/v1/schemas/cluster",
+	} {
+		w = doRequest(s, "ben", http.MethodGet, path)
+		ut.Equal(t, w.Code, http.StatusOK)
+	}
+
+	//non resource rule doesn't apply to resource
+	w = doRequest(s, "ben", http.MethodGet, "/apis/testing/v1/clusters/c2")
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+}
+
+func TestValidatePolicy(t *testing.T) {
+	for _, p := range []*Policy{
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"watch"}}}}}},
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"get"}, Parents: []string{"cluster"}}}}}},
+		{Roles: []Role{{Name: "r"}, {Name: "r"}}},
+		{Bindings: []Binding{{Role: "r", Users: []string{"ben"}}}},
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"get"}, Kinds: []string{"node"}, NonResourcePaths: []string{"/apis"}}}}}},
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"delete"}, NonResourcePaths: []string{"/apis"}}}}}},
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"get"}, NonResourcePaths: []string{"apis"}}}}}},
+		{Roles: []Role{{Name: "r", Rules: []Rule{{Verbs: []string{"get"}, NonResourcePaths: []string{"/*/v1"}}}}}},
+	} {
+		ut.Assert(t, p.Validate() != nil, "")
+	}
+}
@@ -0,0 +1,149 @@
+package rbac
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ben-han-cn/gorest/resource"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	VerbList      = resource.VerbList
+	VerbGet       = resource.VerbGet
+	VerbCreate    = resource.VerbCreate
+	VerbUpdate    = resource.VerbUpdate
+	VerbDelete    = resource.VerbDelete
+	Wildcard      = "*"
+	AnyActionVerb = resource.ActionVerbPrefix + Wildcard
+)
+
+//policy grants the permissions in roles to users and user groups
+//in bindings, there is no deny rule, request is denied unless some
+//rule permits it
+type Policy struct {
+	Roles    []Role    `yaml:"roles"`
+	Bindings []Binding `yaml:"bindings"`
+}
+
+type Role struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+//empty field matches anything, and "*" could be used as any value
+type Rule struct {
+	APIGroups []string `yaml:"apiGroups"`
+	Versions  []string `yaml:"versions"`
+	Kinds     []string `yaml:"kinds"`
+	//path of ancestors like cluster/c1/namespace/*, it matches the
+	//resources under the ancestors in any depth
+	Parents []string `yaml:"parents"`
+	//rule with ids only applies to the resources with the ids, list is
+	//allowed but only the resources with the ids are returned
+	IDs []string `yaml:"ids"`
+	//list, get, create, update, delete, action:<name> or action:*
+	Verbs []string `yaml:"verbs"`
+	//paths of requests which aren't on resource like /apis and /metrics,
+	//path ends with "*" matches the prefix, rule with the paths only
+	//applies to these requests and can't have resource fields
+	NonResourcePaths []string `yaml:"nonResourcePaths"`
+}
+
+type Binding struct {
+	Role string `yaml:"role"`
+	//"*" means any authenticated user
+	Users  []string `yaml:"users"`
+	Groups []string `yaml:"groups"`
+}
+
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parse policy file %s failed:%s", file, err.Error())
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("policy file %s is invalid:%s", file, err.Error())
+	}
+	return &policy, nil
+}
+
+func (p *Policy) Validate() error {
+	roles := make(map[string]bool)
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("role has no name")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("duplicate role %s", role.Name)
+		}
+		roles[role.Name] = true
+
+		for _, rule := range role.Rules {
+			if len(rule.Verbs) == 0 {
+				return fmt.Errorf("rule of role %s has no verb", role.Name)
+			}
+			for _, verb := range rule.Verbs {
+				if isValidVerb(verb) == false {
+					return fmt.Errorf("role %s has unknown verb %s", role.Name, verb)
+				}
+			}
+			if err := validateNonResourceRule(rule); err != nil {
+				return fmt.Errorf("rule of role %s is invalid:%s", role.Name, err.Error())
+			}
+			for _, parent := range rule.Parents {
+				if len(strings.Split(parent, "/"))%2 != 0 {
+					return fmt.Errorf("parent %s of role %s isn't kind/id pairs", parent, role.Name)
+				}
+			}
+		}
+	}
+
+	for _, binding := range p.Bindings {
+		if roles[binding.Role] == false {
+			return fmt.Errorf("binding refers to unknown role %s", binding.Role)
+		}
+	}
+	return nil
+}
+
+func validateNonResourceRule(rule Rule) error {
+	if len(rule.NonResourcePaths) == 0 {
+		return nil
+	}
+
+	if len(rule.APIGroups) != 0 || len(rule.Versions) != 0 || len(rule.Kinds) != 0 ||
+		len(rule.Parents) != 0 || len(rule.IDs) != 0 {
+		return fmt.Errorf("rule with non resource paths has resource fields")
+	}
+	for _, verb := range rule.Verbs {
+		if verb != VerbGet && verb != Wildcard {
+			return fmt.Errorf("verb of non resource paths should be get")
+		}
+	}
+	for _, path := range rule.NonResourcePaths {
+		if path == Wildcard {
+			continue
+		}
+		if strings.HasPrefix(path, "/") == false || strings.Contains(strings.TrimSuffix(path, Wildcard), Wildcard) {
+			return fmt.Errorf("non resource path %s is invalid", path)
+		}
+	}
+	return nil
+}
+
+func isValidVerb(verb string) bool {
+	switch verb {
+	case VerbList, VerbGet, VerbCreate, VerbUpdate, VerbDelete, Wildcard:
+		return true
+	default:
+		return strings.HasPrefix(verb, resource.ActionVerbPrefix) && len(verb) > len(resource.ActionVerbPrefix)
+	}
+}
@@ -0,0 +1,124 @@
+package gorest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+
+	"github.com/ben-han-cn/gorest/codec"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
+)
+
+const (
+	RequestIDKey    = "X-Request-ID"
+	requestIDCtxKey = "gorest.requestID"
+	maxRequestIDLen = 128
+)
+
+var defaultPanicLogger = log.New(os.Stderr, "", log.LstdFlags)
+
+//panic when serve request is recovered, logged by logger with stack and
+//ServerError with request id is returned. if repanic is true, which
+//is useful in test, the panic is raised again after it's logged
+func (s *Server) SetPanicRecovery(logger *log.Logger, repanic bool) {
+	s.panicLogger = logger
+	s.repanic = repanic
+}
+
+//request id is got from X-Request-ID header of request, or generated if
+//request doesn't have it, it's also set to response header
+func GetRequestID(ctx *resource.Context) string {
+	if id, ok := ctx.Get(requestIDCtxKey); ok {
+		return id.(string)
+	}
+	return ""
+}
+
+func requestID(req *http.Request) string {
+	if id := req.Header.Get(RequestIDKey); id != "" && len(id) <= maxRequestIDLen {
+		return id
+	}
+
+	var buf [16]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+//record whether status code or body has been sent, flush and hijack are
+//passed to the underlying writer which is used by watch
+type responseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.started = true
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if ok == false {
+		return nil, nil, fmt.Errorf("response doesn't support hijack")
+	}
+	w.started = true
+	return hijacker.Hijack()
+}
+
+//recover the panic in any phase of the request, like create context,
+//middleware, handler and write response, if response has been started,
+//error can't be returned, the connection is aborted after log
+func (s *Server) recoverPanic(rw *responseWriter, req *http.Request, span trace.Span, id string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	//used by net/http to abort the response silently
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+
+	logger := s.panicLogger
+	if logger == nil {
+		logger = defaultPanicLogger
+	}
+	logger.Printf("panic when serve %s %s from %s with request id %s: %v\n%s",
+		req.Method, req.URL.RequestURI(), req.RemoteAddr, id, r, debug.Stack())
+	err := goresterr.NewAPIError(goresterr.ServerError,
+		fmt.Sprintf("internal server error, request id is %s", id))
+	span.SetError(err)
+	span.SetAttribute("http.status_code", err.Status)
+	if s.repanic {
+		panic(r)
+	}
+	if rw.started {
+		panic(http.ErrAbortHandler)
+	}
+
+	c, negotiateErr := negotiateCodec(req)
+	if negotiateErr != nil {
+		c = codec.JSON
+	}
+	WriteResponseWithCodec(rw, err.Status, err, c)
+}
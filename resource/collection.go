@@ -9,12 +9,37 @@ type ResourceCollection struct {
 	Type         string                            `json:"type,omitempty"`
 	ResourceType string                            `json:"resourceType,omitempty"`
 	Links        map[ResourceLinkType]ResourceLink `json:"links,omitempty"`
+	Actions      map[string]ResourceLink           `json:"actions,omitempty"`
+	Pagination   *Pagination                       `json:"pagination,omitempty"`
 	Resources    []Resource                        `json:"data"`
 
 	collection Resource `json:"-"`
+	sorted     bool     `json:"-"`
+}
+
+//response of deleting resources in a collection
+type DeleteCollectionResult struct {
+	Type         string   `json:"type,omitempty"`
+	ResourceType string   `json:"resourceType,omitempty"`
+	IDs          []string `json:"ids"`
 }
 
 func NewResourceCollection(collection Resource, i interface{}) (*ResourceCollection, error) {
+	var pagination *Pagination
+	var sorted bool
+	if lr, ok := i.(*ListResult); ok {
+		i = lr.Data
+		sorted = lr.Sorted || lr.Paginated
+		if lr.Paginated {
+			total := lr.Total
+			pagination = &Pagination{
+				Total:    &total,
+				Continue: lr.Continue,
+				previous: lr.Previous,
+			}
+		}
+	}
+
 	typ := collection.GetType()
 	rs, err := interfaceToResourceCollection(typ, i)
 	if err != nil {
@@ -23,12 +48,33 @@ func NewResourceCollection(collection Resource, i interface{}) (*ResourceCollect
 		return &ResourceCollection{
 			Type:         "collection",
 			ResourceType: typ,
+			Pagination:   pagination,
 			Resources:    rs,
 			collection:   collection,
+			sorted:       sorted,
 		}, nil
 	}
 }
 
+//resources returned by list handler which doesn't paginate natively
+//will be sliced based on the limit and continue of the request
+func (rc *ResourceCollection) Paginate(ctx *Context) error {
+	query := ctx.Request.URL.Query()
+	if rc.Pagination == nil {
+		return rc.paginate(ctx.pageRequest, query)
+	}
+
+	rc.Pagination.query = query
+	if rc.Pagination.PageSize == 0 {
+		if ctx.pageRequest != nil && ctx.pageRequest.Limit != 0 {
+			rc.Pagination.PageSize = ctx.pageRequest.Limit
+		} else {
+			rc.Pagination.PageSize = len(rc.Resources)
+		}
+	}
+	return nil
+}
+
 func interfaceToResourceCollection(typ string, i interface{}) ([]Resource, error) {
 	if i == nil {
 		return []Resource{}, nil
@@ -91,6 +137,11 @@ func (rc *ResourceCollection) GetCollection() Resource {
 	return rc.collection
 }
 
+//whether resources are sorted by list handler
+func (rc *ResourceCollection) IsSorted() bool {
+	return rc.sorted
+}
+
 func (rc *ResourceCollection) GetResources() []Resource {
 	return rc.Resources
 }
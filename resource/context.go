@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/ben-han-cn/gorest/codec"
 	"github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 const (
@@ -24,14 +26,34 @@ const (
 	NotNull Modifier = "notnull"
 )
 
+const (
+	VerbList         = "list"
+	VerbGet          = "get"
+	VerbCreate       = "create"
+	VerbUpdate       = "update"
+	VerbDelete       = "delete"
+	ActionVerbPrefix = "action:"
+)
+
 type Context struct {
 	Schemas  SchemaManager
 	Request  *http.Request
 	Response http.ResponseWriter
+	//nil for request which isn't on resource like discovery
 	Resource Resource
 	Method   string
 	params   map[string]interface{}
 	filters  []Filter
+	codec    codec.Codec
+
+	pageRequest *PageRequest
+	sortKeys    []SortKey
+	fields      []string
+
+	status int
+	result interface{}
+
+	predicates []ResourcePredicate
 }
 
 type Filter struct {
@@ -42,21 +64,56 @@ type Filter struct {
 
 type Modifier string
 
+type ResourcePredicate func(Resource) bool
+
 func NewContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManager) (*Context, *error.APIError) {
 	r, err := schemas.CreateResourceFromRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
+	query := req.URL.Query()
+	pr, err := genPageRequest(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKeys, err := genSortKeys(query)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := genFields(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Context{
+		Request:     req,
+		Response:    resp,
+		Resource:    r,
+		Schemas:     schemas,
+		Method:      req.Method,
+		params:      make(map[string]interface{}),
+		filters:     genFilters(req.URL),
+		codec:       codec.JSON,
+		pageRequest: pr,
+		sortKeys:    sortKeys,
+		fields:      fields,
+	}, nil
+}
+
+//context of request which isn't on resource, like discovery, it's passed
+//to the middlewares, so they could protect these requests too
+func NewNonResourceContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManager) *Context {
 	return &Context{
 		Request:  req,
 		Response: resp,
-		Resource: r,
 		Schemas:  schemas,
 		Method:   req.Method,
 		params:   make(map[string]interface{}),
-		filters:  genFilters(req.URL),
-	}, nil
+		codec:    codec.JSON,
+	}
 }
 
 func (ctx *Context) Set(key string, value interface{}) {
@@ -72,9 +129,114 @@ func (ctx *Context) GetFilters() []Filter {
 	return ctx.filters
 }
 
+//list handler which paginates natively should return ListResult
+func (ctx *Context) GetPageRequest() *PageRequest {
+	return ctx.pageRequest
+}
+
+//list handler which sorts natively should return ListResult with Sorted set
+func (ctx *Context) GetSortKeys() []SortKey {
+	return ctx.sortKeys
+}
+
+//fields of resource which should be returned, empty means all
+func (ctx *Context) GetFields() []string {
+	return ctx.fields
+}
+
+//codec used to encode response
+func (ctx *Context) GetCodec() codec.Codec {
+	return ctx.codec
+}
+
+func (ctx *Context) SetCodec(c codec.Codec) {
+	ctx.codec = c
+}
+
+//response is written after all the middlewares return, status is 0 if
+//handler writes response by itself like watch
+func (ctx *Context) SetResponse(status int, result interface{}) {
+	ctx.status = status
+	ctx.result = result
+}
+
+func (ctx *Context) GetResponse() (int, interface{}) {
+	return ctx.status, ctx.result
+}
+
+//verb of request, watch is treated as list, patch as update, action is
+//action:<name>, non-resource request only supports get
+func (ctx *Context) GetVerb() string {
+	if ctx.Resource == nil {
+		if ctx.Method == http.MethodGet {
+			return VerbGet
+		}
+		return ""
+	}
+
+	if action := ctx.Resource.GetAction(); action != nil {
+		return ActionVerb(action.Name)
+	}
+
+	switch ctx.Method {
+	case http.MethodGet:
+		if ctx.Resource.GetID() == "" {
+			return VerbList
+		}
+		return VerbGet
+	case http.MethodPost:
+		return VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		return VerbUpdate
+	case http.MethodDelete:
+		return VerbDelete
+	default:
+		return ""
+	}
+}
+
+func ActionVerb(name string) string {
+	return ActionVerbPrefix + name
+}
+
+//span of current phase, handler could start child span with
+//trace.StartSpan(ctx.Request.Context(), name), span context is
+//propagated from traceparent header of request
+func (ctx *Context) GetSpan() trace.Span {
+	return trace.SpanFromContext(ctx.Request.Context())
+}
+
+//resources which don't satisfy the predicate are removed from list and
+//watch result, used by middleware like access control
+func (ctx *Context) AddResourcePredicate(p ResourcePredicate) {
+	ctx.predicates = append(ctx.predicates, p)
+}
+
+func (ctx *Context) IsResourceVisible(r Resource) bool {
+	for _, p := range ctx.predicates {
+		if p(r) == false {
+			return false
+		}
+	}
+	return true
+}
+
+//query parameters used by gorest itself which aren't filters
+var reservedQueryParams = map[string]bool{
+	"pretty":           true,
+	"watch":            true,
+	LimitQueryParam:    true,
+	ContinueQueryParam: true,
+	SortQueryParam:     true,
+	FieldsQueryParam:   true,
+}
+
 func genFilters(url *url.URL) []Filter {
 	filters := make([]Filter, 0)
 	for k, v := range url.Query() {
+		if reservedQueryParams[k] {
+			continue
+		}
 		var filter Filter
 		i := strings.LastIndexAny(k, "_")
 		if i < 0 {
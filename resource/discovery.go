@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+)
+
+//schema of a resource kind is served at versionUrl/schemas/{kind}
+const SchemasPath = "schemas"
+
+type APIVersionList struct {
+	Type     string       `json:"type"`
+	Versions []APIVersion `json:"versions"`
+}
+
+type APIResourceList struct {
+	Type      string        `json:"type"`
+	Group     string        `json:"group"`
+	Version   string        `json:"version"`
+	Resources []APIResource `json:"resources"`
+}
+
+//parents and children are kind names
+type APIResource struct {
+	Name              string       `json:"name"`
+	Kind              string       `json:"kind"`
+	Parents           []string     `json:"parents,omitempty"`
+	Children          []string     `json:"children,omitempty"`
+	CollectionMethods []HttpMethod `json:"collectionMethods,omitempty"`
+	ResourceMethods   []HttpMethod `json:"resourceMethods,omitempty"`
+	Actions           []string     `json:"actions,omitempty"`
+	CollectionActions []string     `json:"collectionActions,omitempty"`
+}
+
+//fields and their constraints, which could be used to generate form
+//and validate input in client side
+type APIResourceSchema struct {
+	Type   string                           `json:"type"`
+	Name   string                           `json:"name"`
+	Kind   string                           `json:"kind"`
+	Fields []resourcefield.FieldDescription `json:"fields"`
+}
@@ -0,0 +1,27 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+const FieldsQueryParam = "fields"
+
+//parsed from fields query parameter like "name,status.phase",
+//nested field is joined by "."
+func genFields(query url.Values) ([]string, *goresterr.APIError) {
+	var fields []string
+	for _, value := range query[FieldsQueryParam] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+				return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("fields %s has invalid field name", value))
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
@@ -12,25 +12,40 @@ const (
 	CreateMethod string = "Create"
 	DeleteMethod string = "Delete"
 	UpdateMethod string = "Update"
+	PatchMethod  string = "Patch"
 	ListMethod   string = "List"
 	GetMethod    string = "Get"
 	ActionMethod string = "Action"
+	WatchMethod  string = "Watch"
+
+	DeleteCollectionMethod string = "DeleteCollection"
+	CollectionActionMethod string = "CollectionAction"
 )
 
 type CreateHandler func(*Context) (Resource, *goresterr.APIError)
 type DeleteHandler func(*Context) *goresterr.APIError
 type UpdateHandler func(*Context) (Resource, *goresterr.APIError)
+type PatchHandler func(*Context) (Resource, *goresterr.APIError)
 type ListHandler func(*Context) interface{}
 type GetHandler func(*Context) Resource
 type ActionHandler func(*Context) (interface{}, *goresterr.APIError)
+type WatchHandler func(*Context) (<-chan WatchEvent, *goresterr.APIError)
+
+//return ids of the deleted resources
+type DeleteCollectionHandler func(*Context) ([]string, *goresterr.APIError)
+type CollectionActionHandler func(*Context) (interface{}, *goresterr.APIError)
 
 type Handler interface {
 	GetCreateHandler() CreateHandler
 	GetDeleteHandler() DeleteHandler
 	GetUpdateHandler() UpdateHandler
+	GetPatchHandler() PatchHandler
 	GetListHandler() ListHandler
 	GetGetHandler() GetHandler
 	GetActionHandler() ActionHandler
+	GetWatchHandler() WatchHandler
+	GetDeleteCollectionHandler() DeleteCollectionHandler
+	GetCollectionActionHandler() CollectionActionHandler
 }
 
 func HandlerAdaptor(obj interface{}) (Handler, error) {
@@ -65,6 +80,13 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 		}
 	}
 
+	if mv := val.MethodByName(PatchMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
+			handler.patchHandler = method
+			hasAnyHandler = true
+		}
+	}
+
 	if mv := val.MethodByName(CreateMethod); mv.IsValid() {
 		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
 			handler.createHandler = method
@@ -79,6 +101,27 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 		}
 	}
 
+	if mv := val.MethodByName(WatchMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (<-chan WatchEvent, *goresterr.APIError)); ok {
+			handler.watchHandler = method
+			hasAnyHandler = true
+		}
+	}
+
+	if mv := val.MethodByName(DeleteCollectionMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) ([]string, *goresterr.APIError)); ok {
+			handler.deleteCollectionHandler = method
+			hasAnyHandler = true
+		}
+	}
+
+	if mv := val.MethodByName(CollectionActionMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (interface{}, *goresterr.APIError)); ok {
+			handler.collectionActionHandler = method
+			hasAnyHandler = true
+		}
+	}
+
 	if hasAnyHandler == false {
 		return nil, fmt.Errorf("handler doesn't have any handle method")
 	} else {
@@ -89,12 +132,16 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 var _ Handler = &DefaultHandler{}
 
 type DefaultHandler struct {
-	createHandler CreateHandler
-	deleteHandler DeleteHandler
-	updateHandler UpdateHandler
-	listHandler   ListHandler
-	getHandler    GetHandler
-	actionHandler ActionHandler
+	createHandler           CreateHandler
+	deleteHandler           DeleteHandler
+	updateHandler           UpdateHandler
+	patchHandler            PatchHandler
+	listHandler             ListHandler
+	getHandler              GetHandler
+	actionHandler           ActionHandler
+	watchHandler            WatchHandler
+	deleteCollectionHandler DeleteCollectionHandler
+	collectionActionHandler CollectionActionHandler
 }
 
 func (h *DefaultHandler) GetCreateHandler() CreateHandler {
@@ -109,6 +156,10 @@ func (h *DefaultHandler) GetUpdateHandler() UpdateHandler {
 	return h.updateHandler
 }
 
+func (h *DefaultHandler) GetPatchHandler() PatchHandler {
+	return h.patchHandler
+}
+
 func (h *DefaultHandler) GetListHandler() ListHandler {
 	return h.listHandler
 }
@@ -121,14 +172,29 @@ func (h *DefaultHandler) GetActionHandler() ActionHandler {
 	return h.actionHandler
 }
 
+func (h *DefaultHandler) GetWatchHandler() WatchHandler {
+	return h.watchHandler
+}
+
+func (h *DefaultHandler) GetDeleteCollectionHandler() DeleteCollectionHandler {
+	return h.deleteCollectionHandler
+}
+
+func (h *DefaultHandler) GetCollectionActionHandler() CollectionActionHandler {
+	return h.collectionActionHandler
+}
+
 func GetCollectionMethods(handler Handler) []HttpMethod {
 	var collectionMethods []HttpMethod
-	if handler.GetListHandler() != nil {
+	if handler.GetListHandler() != nil || handler.GetWatchHandler() != nil {
 		collectionMethods = append(collectionMethods, http.MethodGet)
 	}
-	if handler.GetCreateHandler() != nil {
+	if handler.GetCreateHandler() != nil || handler.GetCollectionActionHandler() != nil {
 		collectionMethods = append(collectionMethods, http.MethodPost)
 	}
+	if handler.GetDeleteCollectionHandler() != nil {
+		collectionMethods = append(collectionMethods, http.MethodDelete)
+	}
 	return collectionMethods
 }
 
@@ -143,6 +209,9 @@ func GetResourceMethods(handler Handler) []HttpMethod {
 	if handler.GetUpdateHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPut)
 	}
+	if handler.GetPatchHandler() != nil {
+		resourceMethods = append(resourceMethods, http.MethodPatch)
+	}
 	if handler.GetActionHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPost)
 	}
@@ -14,13 +14,17 @@ func (h *dumbHandlerTwo) Create(ctx *Context) (Resource, *err.APIError) {
 	return &dumbResource{Number: 60}, nil
 }
 
+func (h *dumbHandlerTwo) DeleteCollection(ctx *Context) ([]string, *err.APIError) {
+	return []string{"1", "2"}, nil
+}
+
 type emptyHandler struct{}
 
 func TestHandlerGen(t *testing.T) {
 	handler, _ := HandlerAdaptor(&DumbHandler{})
 	resourceMethods := GetResourceMethods(handler)
 	collectionMethods := GetCollectionMethods(handler)
-	ut.Equal(t, resourceMethods, []HttpMethod{http.MethodGet, http.MethodDelete, http.MethodPut, http.MethodPost})
+	ut.Equal(t, resourceMethods, []HttpMethod{http.MethodGet, http.MethodDelete, http.MethodPut, http.MethodPatch, http.MethodPost})
 	ut.Equal(t, collectionMethods, []HttpMethod{http.MethodGet, http.MethodPost})
 
 	createResult, err := handler.GetCreateHandler()(nil)
@@ -31,6 +35,10 @@ func TestHandlerGen(t *testing.T) {
 	ut.Assert(t, err == nil, "")
 	ut.Equal(t, updateResult.(*dumbResource).Number, 20)
 
+	patchResult, err := handler.GetPatchHandler()(nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, patchResult.(*dumbResource).Number, 25)
+
 	err = handler.GetDeleteHandler()(nil)
 	ut.Assert(t, err == nil, "")
 
@@ -44,11 +52,19 @@ func TestHandlerGen(t *testing.T) {
 	ut.Equal(t, actionResult.(int), 50)
 	ut.Assert(t, err == nil, "")
 
+	actionResult, err = handler.GetCollectionActionHandler()(nil)
+	ut.Equal(t, actionResult.(int), 55)
+	ut.Assert(t, err == nil, "")
+
 	handler, _ = HandlerAdaptor(&dumbHandlerTwo{})
 	resourceMethods = GetResourceMethods(handler)
 	collectionMethods = GetCollectionMethods(handler)
 	ut.Equal(t, len(resourceMethods), 0)
-	ut.Equal(t, collectionMethods, []HttpMethod{http.MethodPost})
+	ut.Equal(t, collectionMethods, []HttpMethod{http.MethodPost, http.MethodDelete})
+
+	ids, err := handler.GetDeleteCollectionHandler()(nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, ids, []string{"1", "2"})
 
 	_, err_ := HandlerAdaptor(&emptyHandler{})
 	ut.Assert(t, err_ != nil, "")
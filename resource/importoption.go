@@ -0,0 +1,43 @@
+package resource
+
+//options of resource kind which are specified when import
+type ImportOptions struct {
+	//apply filters in Context to the resources returned by list handler
+	GenericFilter bool
+	//middlewares run after the ones registered to server
+	Middlewares []ScopedMiddleware
+	//reject request body which has fields not in go struct
+	StrictFields bool
+}
+
+type ImportOption func(*ImportOptions)
+
+func NewImportOptions(opts ...ImportOption) ImportOptions {
+	var options ImportOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func WithGenericFilter() ImportOption {
+	return func(options *ImportOptions) {
+		options.GenericFilter = true
+	}
+}
+
+func WithStrictFields() ImportOption {
+	return func(options *ImportOptions) {
+		options.StrictFields = true
+	}
+}
+
+func WithMiddleware(m Middleware, scopes ...MiddlewareScope) ImportOption {
+	return func(options *ImportOptions) {
+		sm := ScopedMiddleware{Middleware: m}
+		for _, scope := range scopes {
+			scope(&sm)
+		}
+		options.Middlewares = append(options.Middlewares, sm)
+	}
+}
@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"github.com/ben-han-cn/gorest/error"
+)
+
+//middleware wraps the middlewares after it and the rest handler,
+//next returns their error
+type Middleware func(ctx *Context, next func() *error.APIError) *error.APIError
+
+//middleware bound to the imported resource kind, empty methods and
+//actions mean all the requests, otherwise request matches if its method
+//is in methods or it's an action in actions
+type ScopedMiddleware struct {
+	Middleware   Middleware
+	Methods      []string
+	Actions      []string
+	WithChildren bool
+}
+
+type MiddlewareScope func(*ScopedMiddleware)
+
+func ForMethods(methods ...string) MiddlewareScope {
+	return func(m *ScopedMiddleware) {
+		m.Methods = append(m.Methods, methods...)
+	}
+}
+
+func ForActions(names ...string) MiddlewareScope {
+	return func(m *ScopedMiddleware) {
+		m.Actions = append(m.Actions, names...)
+	}
+}
+
+//middleware also applies to the descendant kinds, so middleware of a
+//top level kind with this scope applies to the whole api version
+func ForChildren() MiddlewareScope {
+	return func(m *ScopedMiddleware) {
+		m.WithChildren = true
+	}
+}
+
+func (m *ScopedMiddleware) Match(method string, action *Action) bool {
+	if len(m.Methods) == 0 && len(m.Actions) == 0 {
+		return true
+	}
+
+	for _, allowed := range m.Methods {
+		if allowed == method {
+			return true
+		}
+	}
+	if action != nil {
+		for _, name := range m.Actions {
+			if name == action.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
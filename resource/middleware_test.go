@@ -0,0 +1,25 @@
+package resource
+
+import (
+	"net/http"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestScopedMiddlewareMatch(t *testing.T) {
+	opts := NewImportOptions(
+		WithMiddleware(nil),
+		WithMiddleware(nil, ForMethods(http.MethodDelete), ForActions("decode")),
+	)
+	all, scoped := opts.Middlewares[0], opts.Middlewares[1]
+
+	ut.Assert(t, all.Match(http.MethodGet, nil), "")
+	ut.Assert(t, all.Match(http.MethodPost, &Action{Name: "encode"}), "")
+
+	ut.Assert(t, scoped.Match(http.MethodDelete, nil), "")
+	ut.Assert(t, scoped.Match(http.MethodPost, &Action{Name: "decode"}), "")
+	ut.Assert(t, scoped.Match(http.MethodPost, &Action{Name: "encode"}) == false, "")
+	ut.Assert(t, scoped.Match(http.MethodPost, nil) == false, "")
+	ut.Assert(t, scoped.WithChildren == false, "")
+}
@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+const (
+	LimitQueryParam    = "limit"
+	ContinueQueryParam = "continue"
+)
+
+//parsed from limit and continue query parameters,
+//Limit is 0 means the client doesn't ask for pagination
+type PageRequest struct {
+	Limit    int
+	Continue string
+}
+
+//list handler which paginates natively should return ListResult with
+//Paginated set, Continue and Previous are the tokens to get next and
+//previous page, empty means there is no more page, handler which
+//paginates should also sort the resources
+type ListResult struct {
+	Data      interface{}
+	Paginated bool
+	Sorted    bool
+	Total     int
+	Continue  string
+	Previous  string
+}
+
+//total is nil when it's unknown, like some resources in the natively
+//paginated page are hidden by resource predicate
+type Pagination struct {
+	Total    *int   `json:"total,omitempty"`
+	PageSize int    `json:"pageSize"`
+	Continue string `json:"continue,omitempty"`
+
+	previous string     `json:"-"`
+	query    url.Values `json:"-"`
+}
+
+func genPageRequest(query url.Values) (*PageRequest, *goresterr.APIError) {
+	pr := &PageRequest{
+		Continue: query.Get(ContinueQueryParam),
+	}
+	if limit := query.Get(LimitQueryParam); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 {
+			return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("limit %s isn't a positive integer", limit))
+		}
+		pr.Limit = l
+	}
+	return pr, nil
+}
+
+//token used by gorest itself is the offset of the next page
+func encodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeOffset(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	d, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid continue token %s", token)
+	}
+	offset, err := strconv.Atoi(string(d))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid continue token %s", token)
+	}
+	return offset, nil
+}
+
+//slice the resources when list handler doesn't paginate
+func (rc *ResourceCollection) paginate(pr *PageRequest, query url.Values) error {
+	if pr == nil || pr.Limit == 0 {
+		return nil
+	}
+
+	offset, err := decodeOffset(pr.Continue)
+	if err != nil {
+		return err
+	}
+
+	total := len(rc.Resources)
+	if offset > total {
+		offset = total
+	}
+	end := offset + pr.Limit
+	if end > total {
+		end = total
+	}
+
+	rc.Resources = rc.Resources[offset:end]
+	rc.Pagination = &Pagination{
+		Total:    &total,
+		PageSize: pr.Limit,
+		query:    query,
+	}
+	if end < total {
+		rc.Pagination.Continue = encodeOffset(end)
+	}
+	if offset > 0 {
+		prev := offset - pr.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		rc.Pagination.previous = encodeOffset(prev)
+	}
+	return nil
+}
+
+//return query of next and previous page based on the query of current page,
+//empty string means there is no such page
+func (p *Pagination) NextQuery() string {
+	return p.pageQuery(p.Continue)
+}
+
+func (p *Pagination) PreviousQuery() string {
+	return p.pageQuery(p.previous)
+}
+
+func (p *Pagination) pageQuery(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	for k, v := range p.query {
+		query[k] = v
+	}
+	query.Set(LimitQueryParam, strconv.Itoa(p.PageSize))
+	query.Set(ContinueQueryParam, token)
+	return query.Encode()
+}
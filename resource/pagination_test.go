@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"net/url"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestPaginate(t *testing.T) {
+	var rs []*dumbResource
+	for i := 0; i < 5; i++ {
+		rs = append(rs, &dumbResource{Number: i})
+	}
+
+	collection := &dumbResource{}
+	collection.SetType(DefaultKindName(dumbResource{}))
+	query := url.Values{"name": []string{"a"}}
+	rc, _ := NewResourceCollection(collection, rs)
+	ut.Assert(t, rc.paginate(&PageRequest{Limit: 2}, query) == nil, "")
+	ut.Equal(t, len(rc.Resources), 2)
+	ut.Equal(t, rc.Resources[0].(*dumbResource).Number, 0)
+	ut.Equal(t, *rc.Pagination.Total, 5)
+	ut.Equal(t, rc.Pagination.PageSize, 2)
+	ut.Equal(t, rc.Pagination.PreviousQuery(), "")
+	next, _ := url.ParseQuery(rc.Pagination.NextQuery())
+	ut.Equal(t, next.Get("name"), "a")
+	ut.Equal(t, next.Get(LimitQueryParam), "2")
+
+	rc, _ = NewResourceCollection(collection, rs)
+	ut.Assert(t, rc.paginate(&PageRequest{Limit: 2, Continue: next.Get(ContinueQueryParam)}, query) == nil, "")
+	ut.Equal(t, rc.Resources[0].(*dumbResource).Number, 2)
+	ut.Assert(t, rc.Pagination.PreviousQuery() != "", "")
+	next, _ = url.ParseQuery(rc.Pagination.NextQuery())
+
+	rc, _ = NewResourceCollection(collection, rs)
+	ut.Assert(t, rc.paginate(&PageRequest{Limit: 2, Continue: next.Get(ContinueQueryParam)}, query) == nil, "")
+	ut.Equal(t, len(rc.Resources), 1)
+	ut.Equal(t, rc.Resources[0].(*dumbResource).Number, 4)
+	ut.Equal(t, rc.Pagination.Continue, "")
+	ut.Equal(t, rc.Pagination.NextQuery(), "")
+
+	rc, _ = NewResourceCollection(collection, rs)
+	ut.Assert(t, rc.paginate(&PageRequest{}, query) == nil, "")
+	ut.Equal(t, len(rc.Resources), 5)
+	ut.Assert(t, rc.Pagination == nil, "")
+
+	ut.Assert(t, rc.paginate(&PageRequest{Limit: 2, Continue: "bad token"}, query) != nil, "")
+
+	_, err := genPageRequest(url.Values{LimitQueryParam: []string{"0"}})
+	ut.Assert(t, err != nil, "")
+}
+
+func TestNativePagination(t *testing.T) {
+	collection := &dumbResource{}
+	collection.SetType(DefaultKindName(dumbResource{}))
+	rc, err := NewResourceCollection(collection, &ListResult{
+		Data:      []*dumbResource{&dumbResource{Number: 1}},
+		Paginated: true,
+		Total:     10,
+		Continue:  "n",
+		Previous:  "p",
+	})
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, len(rc.Resources), 1)
+	ut.Equal(t, *rc.Pagination.Total, 10)
+	ut.Equal(t, rc.Pagination.Continue, "n")
+	ut.Equal(t, rc.Pagination.previous, "p")
+
+	rc, _ = NewResourceCollection(collection, &ListResult{
+		Data: []*dumbResource{&dumbResource{Number: 1}},
+	})
+	ut.Assert(t, rc.Pagination == nil, "")
+}
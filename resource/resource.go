@@ -16,6 +16,8 @@ const (
 	UpdateLink     ResourceLinkType = "update"
 	RemoveLink     ResourceLinkType = "remove"
 	CollectionLink ResourceLinkType = "collection"
+	NextLink       ResourceLinkType = "next"
+	PrevLink       ResourceLinkType = "prev"
 )
 
 type Resource interface {
@@ -25,6 +27,11 @@ type Resource interface {
 	GetID() string
 	SetID(string)
 
+	//version is changed by handler whenever resource is modified,
+	//it's used as ETag for optimistic concurrency control
+	GetResourceVersion() string
+	SetResourceVersion(string)
+
 	GetLinks() map[ResourceLinkType]ResourceLink
 	SetLinks(map[ResourceLinkType]ResourceLink)
 
@@ -57,6 +64,17 @@ type ResourceKind interface {
 	//default value
 	CreateDefaultResource() Resource
 	CreateAction(name string) *Action
+	//names of actions, used to generate discovery and openapi document,
+	//it must return exactly the names CreateAction accepts, otherwise
+	//the action isn't described, a shared table of names helps
+	GetActionNames() []string
+
+	//action applies to the whole collection like
+	//POST /clusters?action=importFromFile, names of the
+	//collection actions are used to generate links, they must agree
+	//with CreateCollectionAction like action names
+	CreateCollectionAction(name string) *Action
+	GetCollectionActionNames() []string
 }
 
 //lowercase singluar
@@ -8,6 +8,7 @@ import (
 type ResourceBase struct {
 	ID                string                            `json:"id,omitempty"`
 	Type              string                            `json:"type,omitempty"`
+	ResourceVersion   string                            `json:"resourceVersion,omitempty"`
 	Links             map[ResourceLinkType]ResourceLink `json:"links,omitempty"`
 	CreationTimestamp ISOTime                           `json:"creationTimestamp,omitempty"`
 	DeletionTimestamp ISOTime                           `json:"deletionTimestamp,omitempty"`
@@ -29,6 +30,18 @@ func (r ResourceBase) CreateAction(name string) *Action {
 	return nil
 }
 
+func (r ResourceBase) GetActionNames() []string {
+	return nil
+}
+
+func (r ResourceBase) CreateCollectionAction(name string) *Action {
+	return nil
+}
+
+func (r ResourceBase) GetCollectionActionNames() []string {
+	return nil
+}
+
 var _ ResourceKind = ResourceBase{}
 
 func (r *ResourceBase) GetID() string {
@@ -39,6 +52,14 @@ func (r *ResourceBase) SetID(id string) {
 	r.ID = id
 }
 
+func (r *ResourceBase) GetResourceVersion() string {
+	return r.ResourceVersion
+}
+
+func (r *ResourceBase) SetResourceVersion(version string) {
+	r.ResourceVersion = version
+}
+
 func (r *ResourceBase) GetLinks() map[ResourceLinkType]ResourceLink {
 	return r.Links
 }
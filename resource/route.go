@@ -6,7 +6,7 @@ import (
 
 type HttpMethod string
 
-var SupportedMethods = []HttpMethod{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPost}
+var SupportedMethods = []HttpMethod{http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPost, http.MethodPatch}
 
 type ResourceRoute map[HttpMethod][]string
 
@@ -1,15 +1,17 @@
 package resource
 
 import (
-	goresterr "github.com/ben-han-cn/gorest/error"
 	"net/http"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/openapi"
 )
 
 type SchemaManager interface {
-	Import(*APIVersion, ResourceKind, interface{}) error
+	Import(*APIVersion, ResourceKind, interface{}, ...ImportOption) error
 
 	//same with import, but will panic if get error
-	MustImport(*APIVersion, ResourceKind, interface{})
+	MustImport(*APIVersion, ResourceKind, interface{}, ...ImportOption)
 
 	//for GET/ DELETE, return empty resource, with id and parent set,
 	//for POST and PUT, the resource unmarshal from body will be returned
@@ -18,10 +20,47 @@ type SchemaManager interface {
 
 	//based on handler to generate route for the resources
 	GenerateResourceRoute() ResourceRoute
+
+	//discovery documents of all the api versions and resources
+	//in one version, nil is returned for unknown version
+	GetAPIVersionList() *APIVersionList
+	GetAPIResourceList(*APIVersion) *APIResourceList
+	//return nil if kind doesn't exist in the version
+	GetAPIResourceSchema(v *APIVersion, kind string) *APIResourceSchema
+
+	//OpenAPI 3 document of all the resources
+	GenerateOpenAPI() *openapi.Document
 }
 
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
 type Schema interface {
+	GetAPIVersion() *APIVersion
 	GetHandler() Handler
+	//middlewares specified when import
+	GetMiddlewares() []ScopedMiddleware
 	AddLinksToResource(r Resource, httpSchemeAndHost string) error
 	AddLinksToResourceCollection(rs *ResourceCollection, httpSchemeAndHost string) error
+
+	//apply the patch to current, the result is unmarshalled into a new
+	//resource which has same id and parent with r, and validated
+	PatchResource(r Resource, current Resource, patchType string, patch []byte) (Resource, *goresterr.APIError)
+
+	//return the resources which match all the filters, resources are
+	//returned directly unless generic filter is enabled when import
+	FilterResources(filters []Filter, rs []Resource) ([]Resource, *goresterr.APIError)
+
+	//check filter names and modifiers against the fields of resource
+	//whether generic filter is enabled or not
+	ValidateFilters(filters []Filter) *goresterr.APIError
+
+	//stable sort the resources in place by the keys
+	SortResources(keys []SortKey, rs []Resource) *goresterr.APIError
+
+	//return json object of each resource which only includes the fields
+	//and common fields like id, type and links
+	PruneResources(fields []string, rs []Resource) ([]map[string]interface{}, *goresterr.APIError)
 }
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+)
+
+//fields of ResourceBase which are always returned
+var commonFields = []string{"id", "type", "resourceVersion", "links"}
+
+//nil node means the whole field is kept
+type fieldTree map[string]fieldTree
+
+func (t fieldTree) add(path []string) {
+	name := path[0]
+	child, ok := t[name]
+	if ok && child == nil {
+		return
+	}
+
+	if len(path) == 1 {
+		t[name] = nil
+	} else {
+		if child == nil {
+			child = make(fieldTree)
+			t[name] = child
+		}
+		child.add(path[1:])
+	}
+}
+
+func (s *Schema) PruneResources(fields []string, rs []resource.Resource) ([]map[string]interface{}, *goresterr.APIError) {
+	tree := make(fieldTree)
+	for _, field := range fields {
+		if _, err := resourcefield.LookupField(reflect.TypeOf(s.resourceKind), field); err != nil {
+			return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("unknown field %s", field))
+		}
+		tree.add(strings.Split(field, "."))
+	}
+	for _, field := range commonFields {
+		tree.add([]string{field})
+	}
+
+	objs := make([]map[string]interface{}, 0, len(rs))
+	for _, r := range rs {
+		obj, err := resourceToJSONObject(r)
+		if err != nil {
+			return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal resource failed:%s", err.Error()))
+		}
+		objs = append(objs, tree.prune(obj))
+	}
+	return objs, nil
+}
+
+func resourceToJSONObject(r resource.Resource) (map[string]interface{}, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+//field which isn't an object like null pointer is kept as it is
+func (t fieldTree) prune(obj map[string]interface{}) map[string]interface{} {
+	pruned := make(map[string]interface{})
+	for name, child := range t {
+		v, ok := obj[name]
+		if ok == false {
+			continue
+		}
+		if child == nil {
+			pruned[name] = v
+		} else if inner, ok := v.(map[string]interface{}); ok {
+			pruned[name] = child.prune(inner)
+		} else {
+			pruned[name] = v
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+func TestPruneResources(t *testing.T) {
+	handler, _ := resource.HandlerAdaptor(&resource.DumbHandler{})
+	s, _ := NewSchema(&version, Pod{}, handler)
+
+	pod := &Pod{
+		Name:        "p1",
+		Count:       10,
+		Annotations: map[string]string{"a": "b"},
+		OtherInfo: OtherPodInfo{
+			Name:    "other",
+			Numbers: []uint32{1, 2},
+		},
+	}
+	pod.SetID("p1")
+	pod.SetType("pod")
+	pod.SetLinks(map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: "/pods/p1"})
+
+	cases := []struct {
+		fields []string
+		result string
+	}{
+		{[]string{"Name"}, `{"Name":"p1","id":"p1","links":{"self":"/pods/p1"},"type":"pod"}`},
+		{[]string{"Count", "OtherInfo.Numbers"}, `{"Count":10,"OtherInfo":{"Numbers":[1,2]},"id":"p1","links":{"self":"/pods/p1"},"type":"pod"}`},
+		{[]string{"OtherInfo.Name", "OtherInfo"}, `{"OtherInfo":{"Name":"other","Numbers":[1,2]},"id":"p1","links":{"self":"/pods/p1"},"type":"pod"}`},
+		{[]string{"OtherInfoPointer.Name"}, `{"OtherInfoPointer":null,"id":"p1","links":{"self":"/pods/p1"},"type":"pod"}`},
+	}
+	for _, tc := range cases {
+		objs, err := s.PruneResources(tc.fields, []resource.Resource{pod})
+		ut.Assert(t, err == nil, "prune %v failed %v", tc.fields, err)
+		d, _ := json.Marshal(objs[0])
+		ut.Equal(t, string(d), tc.result)
+	}
+
+	_, err := s.PruneResources([]string{"Name", "status.phase"}, []resource.Resource{pod})
+	ut.Equal(t, err.ErrorCode, goresterr.InvalidOption)
+}
@@ -0,0 +1,324 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+	"github.com/ben-han-cn/gorest/util"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type filterMatcher struct {
+	field    *resourcefield.FieldPath
+	modifier resource.Modifier
+	kind     util.Kind
+	values   []interface{}
+}
+
+func (s *Schema) FilterResources(filters []resource.Filter, rs []resource.Resource) ([]resource.Resource, *goresterr.APIError) {
+	if s.options.GenericFilter == false || len(filters) == 0 {
+		return rs, nil
+	}
+
+	matchers, err := s.newFilterMatchers(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]resource.Resource, 0, len(rs))
+	for _, r := range rs {
+		v := reflect.ValueOf(r)
+		matched := true
+		for _, m := range matchers {
+			if m.match(v) == false {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+//used when filters select the resources to change, so a mistyped filter
+//isn't ignored
+func (s *Schema) ValidateFilters(filters []resource.Filter) *goresterr.APIError {
+	_, err := s.newFilterMatchers(filters)
+	return err
+}
+
+func (s *Schema) newFilterMatchers(filters []resource.Filter) ([]*filterMatcher, *goresterr.APIError) {
+	matchers := make([]*filterMatcher, 0, len(filters))
+	for _, filter := range filters {
+		if m, err := s.newFilterMatcher(filter); err != nil {
+			return nil, err
+		} else {
+			matchers = append(matchers, m)
+		}
+	}
+	return matchers, nil
+}
+
+func (s *Schema) newFilterMatcher(filter resource.Filter) (*filterMatcher, *goresterr.APIError) {
+	field, err := resourcefield.LookupField(reflect.TypeOf(s.resourceKind), filter.Name)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("filter on unknown field %s", filter.Name))
+	}
+
+	m := &filterMatcher{
+		field:    field,
+		modifier: filter.Modifier,
+		kind:     filterKind(field.Type()),
+	}
+	//any field could be checked against null
+	if m.modifier == resource.Null || m.modifier == resource.NotNull {
+		return m, nil
+	}
+
+	if m.kind == "" {
+		return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("field %s doesn't support filter", filter.Name))
+	}
+	if modifierSupported(m.kind, m.modifier) == false {
+		return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("modifier %s isn't supported by field %s", m.modifier, filter.Name))
+	}
+
+	//enumerable values are separated by comma
+	for _, value := range filter.Value {
+		for _, v := range strings.Split(value, ",") {
+			if parsed, err := m.parseValue(v); err != nil {
+				return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("invalid value %s for filter %s:%s", v, filter.Name, err.Error()))
+			} else {
+				m.values = append(m.values, parsed)
+			}
+		}
+	}
+	if len(m.values) == 0 {
+		return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("filter %s has no value", filter.Name))
+	}
+	return m, nil
+}
+
+//timestamp is regarded as a leaf field, other struct isn't supported
+func filterKind(typ reflect.Type) util.Kind {
+	if typ.Kind() == reflect.Struct && typ.ConvertibleTo(timeType) {
+		return util.Kind("time")
+	}
+
+	switch kind := util.Inspect(typ); kind {
+	case util.Int, util.Uint, util.String, util.Bool, util.IntSlice, util.UintSlice, util.StringSlice:
+		return kind
+	default:
+		return ""
+	}
+}
+
+func modifierSupported(kind util.Kind, modifier resource.Modifier) bool {
+	switch modifier {
+	case resource.Eq, resource.Ne:
+		return true
+	case resource.Lt, resource.Lte, resource.Gt, resource.Gte:
+		return kind == util.Int || kind == util.Uint || kind == util.String || kind == util.Kind("time")
+	case resource.Prefix, resource.Suffix, resource.Like, resource.NotLike:
+		return kind == util.String
+	default:
+		return false
+	}
+}
+
+func (m *filterMatcher) parseValue(v string) (interface{}, error) {
+	switch m.kind {
+	case util.Int, util.IntSlice:
+		return strconv.ParseInt(v, 10, 64)
+	case util.Uint, util.UintSlice:
+		return strconv.ParseUint(v, 10, 64)
+	case util.Bool:
+		return strconv.ParseBool(v)
+	case util.Kind("time"):
+		return time.Parse(time.RFC3339, v)
+	default:
+		if m.modifier == resource.Like || m.modifier == resource.NotLike {
+			return likeToRegexp(v)
+		}
+		return v, nil
+	}
+}
+
+//sql like pattern, "_" matches one character and "%" matches any characters,
+//"\" escapes the next character
+func likeToRegexp(pattern string) (*regexp.Regexp, error) {
+	var buf strings.Builder
+	buf.WriteString("^(?s:")
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '_':
+			buf.WriteString(".")
+		case c == '%':
+			buf.WriteString(".*")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("pattern ends with escape character")
+	}
+	buf.WriteString(")$")
+	return regexp.Compile(buf.String())
+}
+
+func (m *filterMatcher) match(r reflect.Value) bool {
+	v, ok := m.field.Value(r)
+	switch m.modifier {
+	case resource.Null:
+		return ok == false || isEmpty(v)
+	case resource.NotNull:
+		return ok && isEmpty(v) == false
+	}
+
+	//nil field only matches null
+	if ok == false {
+		return false
+	}
+
+	if isSliceKind(m.kind) {
+		contains := false
+		for i := 0; i < v.Len() && contains == false; i++ {
+			contains = m.matchAny(leafValue(m.kind, v.Index(i)))
+		}
+		if m.modifier == resource.Ne {
+			return contains == false
+		}
+		return contains
+	}
+
+	e := leafValue(m.kind, v)
+	switch m.modifier {
+	case resource.Ne, resource.NotLike:
+		return m.matchAny(e) == false
+	case resource.Lt, resource.Lte, resource.Gt, resource.Gte:
+		return m.matchAll(e)
+	default:
+		return m.matchAny(e)
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func isSliceKind(kind util.Kind) bool {
+	return kind == util.IntSlice || kind == util.UintSlice || kind == util.StringSlice
+}
+
+func leafValue(kind util.Kind, v reflect.Value) interface{} {
+	switch kind {
+	case util.Int, util.IntSlice:
+		return v.Int()
+	case util.Uint, util.UintSlice:
+		return v.Uint()
+	case util.Bool:
+		return v.Bool()
+	case util.Kind("time"):
+		return v.Convert(timeType).Interface()
+	default:
+		return v.String()
+	}
+}
+
+func (m *filterMatcher) matchAny(e interface{}) bool {
+	for _, value := range m.values {
+		if m.matchValue(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *filterMatcher) matchAll(e interface{}) bool {
+	for _, value := range m.values {
+		if m.matchValue(e, value) == false {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *filterMatcher) matchValue(e, value interface{}) bool {
+	switch m.modifier {
+	case resource.Prefix:
+		return strings.HasPrefix(e.(string), value.(string))
+	case resource.Suffix:
+		return strings.HasSuffix(e.(string), value.(string))
+	case resource.Like, resource.NotLike:
+		return value.(*regexp.Regexp).MatchString(e.(string))
+	}
+
+	c := compareValue(e, value)
+	switch m.modifier {
+	case resource.Lt:
+		return c < 0
+	case resource.Lte:
+		return c <= 0
+	case resource.Gt:
+		return c > 0
+	case resource.Gte:
+		return c >= 0
+	default:
+		return c == 0
+	}
+}
+
+//a and b should have same type
+func compareValue(a, b interface{}) int {
+	switch av := a.(type) {
+	case int64:
+		bv := b.(int64)
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+	case uint64:
+		bv := b.(uint64)
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+	case string:
+		return strings.Compare(av, b.(string))
+	case bool:
+		if av != b.(bool) {
+			if av {
+				return 1
+			}
+			return -1
+		}
+	case time.Time:
+		bv := b.(time.Time)
+		if av.Before(bv) {
+			return -1
+		} else if av.After(bv) {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+func newFilter(name string, modifier resource.Modifier, values ...string) resource.Filter {
+	return resource.Filter{
+		Name:     name,
+		Modifier: modifier,
+		Value:    values,
+	}
+}
+
+func TestFilterResources(t *testing.T) {
+	handler, _ := resource.HandlerAdaptor(&resource.DumbHandler{})
+	s, err := NewSchema(&version, Pod{}, handler, resource.WithGenericFilter())
+	ut.Assert(t, err == nil, "")
+
+	now := time.Now()
+	var pods []resource.Resource
+	for i, name := range []string{"web_1", "web_2", "db%1", "cache"} {
+		pod := &Pod{
+			Name:  name,
+			Count: uint32(i),
+			OtherInfo: OtherPodInfo{
+				Numbers: []uint32{uint32(i), uint32(i + 1)},
+			},
+		}
+		if i%2 == 0 {
+			pod.OtherInfoPointer = &OtherPodInfo{Name: name}
+		}
+		pod.SetID(name)
+		pod.SetCreationTimestamp(now.Add(time.Duration(i) * time.Hour))
+		pods = append(pods, pod)
+	}
+
+	cases := []struct {
+		filters []resource.Filter
+		ids     []string
+	}{
+		{nil, []string{"web_1", "web_2", "db%1", "cache"}},
+		{[]resource.Filter{newFilter("Name", resource.Eq, "web_1,cache")}, []string{"web_1", "cache"}},
+		{[]resource.Filter{newFilter("Name", resource.Ne, "web_1", "cache")}, []string{"web_2", "db%1"}},
+		{[]resource.Filter{newFilter("Name", resource.Prefix, "web"), newFilter("Count", resource.Gt, "0")}, []string{"web_2"}},
+		{[]resource.Filter{newFilter("Name", resource.Suffix, "1")}, []string{"web_1", "db%1"}},
+		{[]resource.Filter{newFilter("Name", resource.Like, "web\\__")}, []string{"web_1", "web_2"}},
+		{[]resource.Filter{newFilter("Name", resource.Like, "%\\%%")}, []string{"db%1"}},
+		{[]resource.Filter{newFilter("Name", resource.NotLike, "%a%")}, []string{"web_1", "web_2", "db%1"}},
+		{[]resource.Filter{newFilter("Count", resource.Gte, "1"), newFilter("Count", resource.Lt, "3")}, []string{"web_2", "db%1"}},
+		{[]resource.Filter{newFilter("OtherInfo.Numbers", resource.Eq, "3")}, []string{"db%1", "cache"}},
+		{[]resource.Filter{newFilter("OtherInfo.Numbers", resource.Ne, "1")}, []string{"db%1", "cache"}},
+		{[]resource.Filter{newFilter("OtherInfoPointer.Name", resource.Eq, "db%1")}, []string{"db%1"}},
+		{[]resource.Filter{newFilter("OtherInfoPointer", resource.Null)}, []string{"web_2", "cache"}},
+		{[]resource.Filter{newFilter("OtherInfoPointer.Name", resource.NotNull)}, []string{"web_1", "db%1"}},
+		{[]resource.Filter{newFilter("creationTimestamp", resource.Gt, now.Add(90*time.Minute).Format(time.RFC3339))}, []string{"db%1", "cache"}},
+		{[]resource.Filter{newFilter("id", resource.Eq, "cache")}, []string{"cache"}},
+	}
+	for _, tc := range cases {
+		rs, err := s.FilterResources(tc.filters, pods)
+		ut.Assert(t, err == nil, "filter %v failed %v", tc.filters, err)
+		var ids []string
+		for _, r := range rs {
+			ids = append(ids, r.GetID())
+		}
+		ut.Equal(t, ids, tc.ids)
+	}
+
+	invalidCases := []struct {
+		filter resource.Filter
+		code   goresterr.ErrorCode
+	}{
+		{newFilter("unknown", resource.Eq, "a"), goresterr.InvalidOption},
+		{newFilter("Name", resource.VerifyModifier("x"), "a"), goresterr.InvalidOption},
+		{newFilter("Count", resource.Prefix, "1"), goresterr.InvalidOption},
+		{newFilter("Annotations", resource.Eq, "a"), goresterr.InvalidOption},
+		{newFilter("Count", resource.Eq, "a"), goresterr.InvalidFormat},
+		{newFilter("creationTimestamp", resource.Lt, "yesterday"), goresterr.InvalidFormat},
+	}
+	for _, tc := range invalidCases {
+		_, err := s.FilterResources([]resource.Filter{tc.filter}, pods)
+		ut.Equal(t, err.ErrorCode, tc.code)
+	}
+
+	//filters are ignored without generic filter
+	s, _ = NewSchema(&version, Pod{}, handler)
+	rs, err_ := s.FilterResources([]resource.Filter{newFilter("unknown", resource.Eq, "a")}, pods)
+	ut.Assert(t, err_ == nil, "")
+	ut.Equal(t, len(rs), len(pods))
+}
@@ -0,0 +1,355 @@
+package schema
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/openapi"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+)
+
+const (
+	errorComponent                  = "error"
+	deleteCollectionResultComponent = "deleteCollectionResult"
+	jsonContentType                 = "application/json"
+)
+
+func (m *SchemaManager) GenerateOpenAPI() *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "gorest",
+		},
+		Paths: make(map[string]openapi.PathItem),
+		Components: openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				errorComponent:                  errorSchema(),
+				deleteCollectionResultComponent: typeSchema(reflect.TypeOf(resource.DeleteCollectionResult{})),
+			},
+			Responses: map[string]openapi.Response{
+				errorComponent: openapi.Response{
+					Description: "error",
+					Content:     jsonContent(openapi.Ref(errorComponent)),
+				},
+			},
+		},
+	}
+
+	var versions []string
+	for _, vs := range m.schemas {
+		versions = append(versions, path.Join(vs.version.Group, vs.version.Version))
+		for _, s := range vs.toplevelSchemas {
+			s.addToOpenAPI(doc, nil)
+		}
+	}
+	doc.Info.Version = strings.Join(versions, ",")
+	return doc
+}
+
+func errorSchema() *openapi.Schema {
+	schema := typeSchema(reflect.TypeOf(goresterr.APIError{}))
+	for _, code := range goresterr.ErrorCodes {
+		schema.Properties["code"].Enum = append(schema.Properties["code"].Enum, code.Code)
+	}
+	return schema
+}
+
+//kind with same name may exists in different versions
+func (s *Schema) componentName() string {
+	return strings.Join([]string{s.version.Group, s.version.Version, s.resourceKindName}, ".")
+}
+
+func (s *Schema) addToOpenAPI(doc *openapi.Document, parents []*Schema) {
+	name := s.componentName()
+	if _, ok := doc.Components.Schemas[name]; ok == false {
+		doc.Components.Schemas[name] = descriptionsSchema(s.descriptions)
+		collection := typeSchema(reflect.TypeOf(resource.ResourceCollection{}))
+		collection.Properties["data"].Items = openapi.Ref(name)
+		doc.Components.Schemas[name+".collection"] = collection
+	}
+
+	var params []openapi.Parameter
+	for _, parent := range parents {
+		params = append(params, pathParameter(parent))
+	}
+	collectionPath := s.generateCollectionPath(parents, nil, "")
+	if item := s.collectionPathItem(collectionPath, params); len(item) > 0 {
+		doc.Paths[openapiPath(collectionPath)] = item
+	}
+	resourcePath := path.Join(collectionPath, s.urlIdSegment())
+	if item := s.resourcePathItem(resourcePath, append(params, pathParameter(s))); len(item) > 0 {
+		doc.Paths[openapiPath(resourcePath)] = item
+	}
+
+	for _, child := range s.children {
+		child.addToOpenAPI(doc, append(parents, s))
+	}
+}
+
+func (s *Schema) collectionPathItem(p string, params []openapi.Parameter) openapi.PathItem {
+	name := s.componentName()
+	item := make(openapi.PathItem)
+	for _, method := range resource.GetCollectionMethods(s.handler) {
+		var op *openapi.Operation
+		switch method {
+		case http.MethodGet:
+			op = newOperation("list", p, s.resourceName, params)
+			op.Parameters = append(op.Parameters,
+				queryParameter(resource.FieldsQueryParam, "string", "comma separated fields to return"),
+				queryParameter(resource.SortQueryParam, "string", "comma separated fields to sort by, prefix - for descending order"),
+				queryParameter(resource.LimitQueryParam, "integer", "max count of resources in one page"),
+				queryParameter(resource.ContinueQueryParam, "string", "token of the next page"))
+			if s.handler.GetWatchHandler() != nil {
+				op.Parameters = append(op.Parameters, queryParameter("watch", "boolean", "watch the changes of resources"))
+			}
+			op.Responses["200"] = jsonResponse("collection of "+s.resourceName, openapi.Ref(name+".collection"))
+		case http.MethodPost:
+			op = newOperation("create", p, s.resourceName, params)
+			var bodies []*openapi.Schema
+			if s.handler.GetCreateHandler() != nil {
+				bodies = append(bodies, openapi.Ref(name))
+				op.Responses["201"] = jsonResponse("created "+s.resourceKindName, openapi.Ref(name))
+			}
+			if names := s.resourceKind.GetCollectionActionNames(); len(names) > 0 && s.handler.GetCollectionActionHandler() != nil {
+				param := queryParameter("action", "string", "name of collection action")
+				param.Schema.Enum = names
+				param.Required = len(bodies) == 0
+				op.Parameters = append(op.Parameters, param)
+				for _, name := range names {
+					bodies = append(bodies, actionInputSchema(s.resourceKind.CreateCollectionAction(name))...)
+				}
+				op.Responses["200"] = jsonResponse("result of collection action", &openapi.Schema{})
+			}
+			op.RequestBody = requestBody(bodies)
+		case http.MethodDelete:
+			op = newOperation("deleteCollection", p, s.resourceName, params)
+			op.Responses["200"] = jsonResponse("ids of deleted "+s.resourceName, openapi.Ref(deleteCollectionResultComponent))
+		}
+		item[strings.ToLower(string(method))] = op
+	}
+	return item
+}
+
+func (s *Schema) resourcePathItem(p string, params []openapi.Parameter) openapi.PathItem {
+	name := s.componentName()
+	item := make(openapi.PathItem)
+	for _, method := range resource.GetResourceMethods(s.handler) {
+		var op *openapi.Operation
+		switch method {
+		case http.MethodGet:
+			op = newOperation("get", p, s.resourceName, params)
+			op.Parameters = append(op.Parameters, queryParameter(resource.FieldsQueryParam, "string", "comma separated fields to return"))
+			op.Responses["200"] = jsonResponse(s.resourceKindName, openapi.Ref(name))
+		case http.MethodPut:
+			op = newOperation("update", p, s.resourceName, params)
+			op.RequestBody = requestBody([]*openapi.Schema{openapi.Ref(name)})
+			op.Responses["200"] = jsonResponse("updated "+s.resourceKindName, openapi.Ref(name))
+		case http.MethodPatch:
+			op = newOperation("patch", p, s.resourceName, params)
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content: map[string]openapi.MediaType{
+					resource.MergePatchType: openapi.MediaType{Schema: &openapi.Schema{Type: "object"}},
+					resource.JSONPatchType: openapi.MediaType{Schema: &openapi.Schema{
+						Type:  "array",
+						Items: &openapi.Schema{Type: "object"},
+					}},
+				},
+			}
+			op.Responses["200"] = jsonResponse("patched "+s.resourceKindName, openapi.Ref(name))
+		case http.MethodDelete:
+			op = newOperation("delete", p, s.resourceName, params)
+			op.Responses["204"] = openapi.Response{Description: "deleted"}
+		case http.MethodPost:
+			op = newOperation("action", p, s.resourceName, params)
+			param := queryParameter("action", "string", "name of action")
+			param.Schema.Enum = s.resourceKind.GetActionNames()
+			param.Required = true
+			op.Parameters = append(op.Parameters, param)
+			var bodies []*openapi.Schema
+			for _, name := range s.resourceKind.GetActionNames() {
+				bodies = append(bodies, actionInputSchema(s.resourceKind.CreateAction(name))...)
+			}
+			op.RequestBody = requestBody(bodies)
+			op.Responses["200"] = jsonResponse("result of action", &openapi.Schema{})
+		}
+		item[strings.ToLower(string(method))] = op
+	}
+	return item
+}
+
+//operation id is generated from verb and the static segments of path
+//since child resource may have several paths
+func newOperation(verb, p, tag string, params []openapi.Parameter) *openapi.Operation {
+	segments := []string{verb}
+	for _, segment := range strings.Split(strings.TrimPrefix(p, resource.GroupPrefix+"/"), "/") {
+		if strings.HasPrefix(segment, ":") == false {
+			segments = append(segments, segment)
+		}
+	}
+	return &openapi.Operation{
+		OperationID: strings.Join(segments, "_"),
+		Tags:        []string{tag},
+		Parameters:  append([]openapi.Parameter{}, params...),
+		Responses: map[string]openapi.Response{
+			"default": openapi.Response{Ref: "#/components/responses/" + errorComponent},
+		},
+	}
+}
+
+//:cluster_id -> {cluster_id}
+func openapiPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParameter(s *Schema) openapi.Parameter {
+	return openapi.Parameter{
+		Name:     strings.TrimPrefix(s.urlIdSegment(), ":"),
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string"},
+	}
+}
+
+func queryParameter(name, typ, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: typ},
+	}
+}
+
+func jsonContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{
+		jsonContentType: openapi.MediaType{Schema: schema},
+	}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) openapi.Response {
+	return openapi.Response{
+		Description: description,
+		Content:     jsonContent(schema),
+	}
+}
+
+func requestBody(schemas []*openapi.Schema) *openapi.RequestBody {
+	switch len(schemas) {
+	case 0:
+		return nil
+	case 1:
+		return &openapi.RequestBody{Required: true, Content: jsonContent(schemas[0])}
+	default:
+		return &openapi.RequestBody{Required: true, Content: jsonContent(&openapi.Schema{OneOf: schemas})}
+	}
+}
+
+//action without input has no request body
+func actionInputSchema(action *resource.Action) []*openapi.Schema {
+	if action == nil || action.Input == nil {
+		return nil
+	}
+	return []*openapi.Schema{typeSchema(reflect.TypeOf(action.Input))}
+}
+
+func typeSchema(typ reflect.Type) *openapi.Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return &openapi.Schema{}
+	}
+
+	descriptions, err := resourcefield.Describe(typ)
+	if err != nil {
+		return &openapi.Schema{Type: "object"}
+	}
+	return descriptionsSchema(descriptions)
+}
+
+func descriptionsSchema(descriptions []resourcefield.FieldDescription) *openapi.Schema {
+	schema := &openapi.Schema{
+		Type:       "object",
+		Properties: make(map[string]*openapi.Schema),
+	}
+	for _, desc := range descriptions {
+		schema.Properties[desc.Name] = fieldSchema(desc)
+		if required, ok := desc.Constraints["required"].(bool); ok && required {
+			schema.Required = append(schema.Required, desc.Name)
+		}
+	}
+	return schema
+}
+
+func fieldSchema(desc resourcefield.FieldDescription) *openapi.Schema {
+	var schema *openapi.Schema
+	switch desc.Type {
+	case "int":
+		schema = &openapi.Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		var min int64
+		schema = &openapi.Schema{Type: "integer", Format: "int64", Minimum: &min}
+	case "float":
+		schema = &openapi.Schema{Type: "number"}
+	case "string":
+		schema = &openapi.Schema{Type: "string"}
+	case "bool":
+		schema = &openapi.Schema{Type: "boolean"}
+	case "time":
+		schema = &openapi.Schema{Type: "string", Format: "date-time"}
+	case "array":
+		schema = &openapi.Schema{Type: "array", Items: fieldSchema(*desc.Elem)}
+	case "map":
+		schema = &openapi.Schema{Type: "object", AdditionalProperties: fieldSchema(*desc.Elem)}
+	case "struct":
+		schema = descriptionsSchema(desc.Fields)
+	default:
+		schema = &openapi.Schema{}
+	}
+	applyConstraints(schema, desc.Constraints)
+	return schema
+}
+
+//validators of slice and map apply to the elements, ranges checked by
+//validators exclude the max value
+func applyConstraints(schema *openapi.Schema, constraints map[string]interface{}) {
+	if schema.Items != nil {
+		applyConstraints(schema.Items, constraints)
+		return
+	} else if schema.AdditionalProperties != nil {
+		applyConstraints(schema.AdditionalProperties, constraints)
+		return
+	}
+
+	for k, v := range constraints {
+		switch k {
+		case "min":
+			min := v.(int64)
+			schema.Minimum = &min
+		case "max":
+			max := v.(int64)
+			schema.Maximum = &max
+			schema.ExclusiveMaximum = true
+		case "minLen":
+			minLen := v.(int64)
+			schema.MinLength = &minLen
+		case "maxLen":
+			maxLen := v.(int64) - 1
+			schema.MaxLength = &maxLen
+		case "options":
+			schema.Enum = v.([]string)
+		case "isDomain":
+			schema.Format = "hostname"
+		}
+	}
+}
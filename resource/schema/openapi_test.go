@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	doc := createSchemaManager().GenerateOpenAPI()
+	ut.Equal(t, doc.Info.Version, "testing/v1")
+	ut.Equal(t, len(doc.Components.Schemas["error"].Properties["code"].Enum), len(goresterr.ErrorCodes))
+
+	expectPaths := []string{
+		"/apis/testing/v1/clusters",
+		"/apis/testing/v1/clusters/{cluster_id}",
+		"/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/deployments/{deployment_id}/pods",
+		"/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/daemonsets/{daemonset_id}/pods/{pod_id}",
+	}
+	for _, p := range expectPaths {
+		_, ok := doc.Paths[p]
+		ut.Assert(t, ok, "path %s should exist", p)
+	}
+
+	operationIDs := make(map[string]bool)
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			ut.Assert(t, operationIDs[op.OperationID] == false, "duplicate operation id %s", op.OperationID)
+			operationIDs[op.OperationID] = true
+		}
+	}
+
+	pod := doc.Paths["/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/statefulsets/{statefulset_id}/pods/{pod_id}"]
+	get := pod["get"]
+	ut.Equal(t, get.OperationID, "get_testing_v1_clusters_namespaces_statefulsets_pods")
+	ut.Equal(t, len(get.Parameters), 5)
+	ut.Equal(t, get.Parameters[3].Name, "pod_id")
+	ut.Equal(t, get.Responses["200"].Content["application/json"].Schema.Ref, "#/components/schemas/testing.v1.pod")
+	ut.Equal(t, get.Responses["default"].Ref, "#/components/responses/error")
+
+	action := pod["post"]
+	ut.Equal(t, action.Parameters[4].Schema.Enum, []string{"move"})
+	ut.Equal(t, action.RequestBody.Content["application/json"].Schema.Properties["nodeName"].Type, "string")
+
+	pods := doc.Paths["/apis/testing/v1/clusters/{cluster_id}/namespaces/{namespace_id}/statefulsets/{statefulset_id}/pods"]
+	create := pods["post"]
+	ut.Equal(t, len(create.RequestBody.Content["application/json"].Schema.OneOf), 2)
+	ut.Equal(t, create.Parameters[3].Schema.Enum, []string{"evict"})
+
+	collection := doc.Components.Schemas["testing.v1.pod.collection"]
+	ut.Equal(t, collection.Properties["data"].Items.Ref, "#/components/schemas/testing.v1.pod")
+	podSchema := doc.Components.Schemas["testing.v1.pod"]
+	ut.Equal(t, podSchema.Properties["creationTimestamp"].Format, "date-time")
+	ut.Equal(t, podSchema.Properties["OtherInfoSlice"].Items.Properties["Numbers"].Items.Type, "integer")
+}
+
+func TestOpenAPIConstraints(t *testing.T) {
+	type option string
+	type testStruct struct {
+		Name    string            `json:"name" rest:"required=true,minLen=1,maxLen=10"`
+		Count   int               `json:"count" rest:"min=1,max=10"`
+		Domains []string          `json:"domains" rest:"isDomain=true"`
+		Options map[string]option `json:"options" rest:"options=a|b"`
+	}
+
+	schema := typeSchema(reflect.TypeOf(&testStruct{}))
+	ut.Equal(t, schema.Required, []string{"name"})
+	name := schema.Properties["name"]
+	ut.Equal(t, *name.MinLength, int64(1))
+	ut.Equal(t, *name.MaxLength, int64(9))
+	count := schema.Properties["count"]
+	ut.Equal(t, *count.Minimum, int64(1))
+	ut.Equal(t, *count.Maximum, int64(10))
+	ut.Assert(t, count.ExclusiveMaximum, "")
+	ut.Equal(t, schema.Properties["domains"].Items.Format, "hostname")
+	ut.Equal(t, schema.Properties["options"].AdditionalProperties.Enum, []string{"a", "b"})
+}
@@ -0,0 +1,346 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+func applyPatch(patchType string, doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := unmarshalUseNumber(doc, &target); err != nil {
+		return nil, err
+	}
+
+	var patched interface{}
+	switch patchType {
+	case resource.MergePatchType:
+		var p interface{}
+		if err := unmarshalUseNumber(patch, &p); err != nil {
+			return nil, fmt.Errorf("merge patch isn't valid json:%s", err.Error())
+		}
+		patched = mergePatch(target, p)
+	case resource.JSONPatchType:
+		var ops []jsonPatchOperation
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return nil, fmt.Errorf("json patch isn't a list of operations:%s", err.Error())
+		}
+		var err error
+		if patched, err = jsonPatch(target, ops); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown patch type %s", patchType)
+	}
+	return json.Marshal(patched)
+}
+
+//rfc7386
+func mergePatch(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if ok == false {
+		return patch
+	}
+
+	tm, ok := target.(map[string]interface{})
+	if ok == false {
+		tm = make(map[string]interface{})
+	}
+
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergePatch(tm[k], v)
+		}
+	}
+	return tm
+}
+
+//rfc6902
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func jsonPatch(doc interface{}, ops []jsonPatchOperation) (interface{}, error) {
+	for i, op := range ops {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d %s failed:%s", i, op.Op, err.Error())
+		}
+	}
+	return doc, nil
+}
+
+func (op *jsonPatchOperation) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("value is missing")
+	}
+
+	var v interface{}
+	if err := unmarshalUseNumber(op.Value, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+//number is kept as json.Number, so large integer isn't rounded to float64
+//when the document is encoded again
+func unmarshalUseNumber(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+//numbers are compared by value, so 1 equals to 1.0
+func jsonEqual(a, b interface{}) bool {
+	var x, y interface{}
+	da, _ := json.Marshal(a)
+	db, _ := json.Marshal(b)
+	json.Unmarshal(da, &x)
+	json.Unmarshal(db, &y)
+	return reflect.DeepEqual(x, y)
+}
+
+func (op *jsonPatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case "replace":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		if doc, _, err = removeValue(doc, path); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "move":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("can't move %s into its child %s", op.From, op.Path)
+		}
+		doc, v, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, deepCopy(v))
+	case "test":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		old, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if jsonEqual(old, v) == false {
+			return nil, fmt.Errorf("value of %s isn't %s", op.Path, string(op.Value))
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation")
+	}
+}
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("path %s doesn't start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %s", token)
+	}
+	return i, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[token]
+			if ok == false {
+				return nil, fmt.Errorf("member %s doesn't exist", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("%s isn't in an object or array", token)
+		}
+	}
+	return doc, nil
+}
+
+//modify the parent container of the last token in path, return the new doc,
+//since container may be reallocated, all the containers in the path are reassigned
+func modifyContainer(doc interface{}, path []string, modify func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return modify(doc, path[0])
+	}
+
+	token := path[0]
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[token]
+		if ok == false {
+			return nil, fmt.Errorf("member %s doesn't exist", token)
+		}
+		child, err := modifyContainer(child, path[1:], modify)
+		if err != nil {
+			return nil, err
+		}
+		c[token] = child
+		return c, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := modifyContainer(c[i], path[1:], modify)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = child
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%s isn't in an object or array", token)
+	}
+}
+
+func addValue(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+
+	return modifyContainer(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = v
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, v), nil
+			}
+			i, err := arrayIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = v
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%s isn't in an object or array", token)
+		}
+	})
+}
+
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("can't remove the whole document")
+	}
+
+	var removed interface{}
+	doc, err := modifyContainer(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			v, ok := c[token]
+			if ok == false {
+				return nil, fmt.Errorf("member %s doesn't exist", token)
+			}
+			removed = v
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i], c[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%s isn't in an object or array", token)
+		}
+	})
+	return doc, removed, err
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, e := range c {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(c))
+		for i, e := range c {
+			s[i] = deepCopy(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+	}
+
+	for _, tc := range cases {
+		result, err := applyPatch(resource.MergePatchType, []byte(tc.doc), []byte(tc.patch))
+		ut.Assert(t, err == nil, "patch %s failed %v", tc.patch, err)
+		ut.Equal(t, string(result), tc.result)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		result string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{`{"foo":{"bar":1}}`, `[{"op":"add","path":"/foo/bar","value":null}]`, `{"foo":{"bar":null}}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"bar":[1]}}`, `[{"op":"copy","from":"/foo/bar","path":"/baz"},{"op":"add","path":"/baz/-","value":2}]`, `{"baz":[1,2],"foo":{"bar":[1]}}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"test","path":"/a~1b","value":1},{"op":"remove","path":"/m~0n"}]`, `{"a/b":1}`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	}
+
+	for _, tc := range cases {
+		result, err := applyPatch(resource.JSONPatchType, []byte(tc.doc), []byte(tc.patch))
+		ut.Assert(t, err == nil, "patch %s failed %v", tc.patch, err)
+		ut.Equal(t, string(result), tc.result)
+	}
+
+	invalidCases := []struct {
+		doc   string
+		patch string
+	}{
+		{`{"foo":"bar"}`, `{"op":"add","path":"/baz","value":"qux"}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"baz","value":1}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bar","value":1}]`},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":1}]`},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{`{"foo":"bar"}`, `[{"op":"test","path":"/foo","value":"baz"}]`},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`},
+		{`{"foo":"bar"}`, `[{"op":"unknown","path":"/foo"}]`},
+	}
+	for _, tc := range invalidCases {
+		_, err := applyPatch(resource.JSONPatchType, []byte(tc.doc), []byte(tc.patch))
+		ut.Assert(t, err != nil, "patch %s should fail", tc.patch)
+	}
+}
+
+func TestPatchResource(t *testing.T) {
+	mgr := createSchemaManager()
+	url := "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods/p1"
+	req, _ := http.NewRequest(http.MethodPatch, url, nil)
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "")
+
+	current := &Pod{
+		Name:        "p1",
+		Count:       10,
+		Annotations: map[string]string{"a": "b", "c": "d"},
+	}
+	current.SetID("p1")
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"Count":       30,
+		"Annotations": map[string]interface{}{"a": nil, "e": "f"},
+	})
+	patched, err := r.GetSchema().PatchResource(r, current, resource.MergePatchType, patch)
+	ut.Assert(t, err == nil, "patch failed %v", err)
+	pod := patched.(*Pod)
+	ut.Equal(t, pod.Name, "p1")
+	ut.Equal(t, pod.Count, uint32(30))
+	ut.Equal(t, pod.Annotations, map[string]string{"c": "d", "e": "f"})
+	ut.Equal(t, pod.GetID(), "p1")
+	ut.Equal(t, pod.GetType(), "pod")
+	ut.Equal(t, pod.GetParent().GetID(), "d1")
+	//default value isn't used for patch
+	ut.Assert(t, pod.OtherInfoPointer == nil, "")
+
+	patch = []byte(`[{"op":"replace","path":"/Name","value":"p2"}]`)
+	patched, err = r.GetSchema().PatchResource(r, current, resource.JSONPatchType, patch)
+	ut.Assert(t, err == nil, "patch failed %v", err)
+	ut.Equal(t, patched.(*Pod).Name, "p2")
+
+	_, err = r.GetSchema().PatchResource(r, current, "application/json", patch)
+	ut.Equal(t, err.ErrorCode, goresterr.UnsupportedMediaType)
+}
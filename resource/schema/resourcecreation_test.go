@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/ben-han-cn/gorest/resource"
 )
 
 type podGenJson struct {
@@ -233,3 +234,66 @@ func TestAction(t *testing.T) {
 	_, err = mgr.CreateResourceFromRequest(req)
 	ut.Assert(t, err != nil, "")
 }
+
+func TestCollectionAction(t *testing.T) {
+	mgr := createSchemaManager()
+	url := "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=evict"
+	reqBody, _ := json.Marshal(Location{NodeName: "n1"})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(string(reqBody)))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, r.GetID(), "")
+	action := r.GetAction()
+	ut.Equal(t, action.Name, "evict")
+	ut.Equal(t, action.Input.(*Location).NodeName, "n1")
+
+	//resource action isn't available on collection and vice versa
+	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=move"
+	req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(string(reqBody)))
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+
+	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods/p1?action=evict"
+	req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(string(reqBody)))
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+
+	rc, _ := resource.NewResourceCollection(r, nil)
+	r.GetSchema().AddLinksToResourceCollection(rc, "")
+	ut.Equal(t, rc.Actions, map[string]resource.ResourceLink{
+		"evict": "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=evict",
+	})
+}
+
+func TestCreateResourceWithCodec(t *testing.T) {
+	mgr := createSchemaManager()
+	url := "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods/"
+	cases := []struct {
+		contentType string
+		body        string
+		isValid     bool
+	}{
+		{"application/yaml", "Name: p1\nCount: 30\nAnnotations:\n  a: b\n", true},
+		{"application/json; charset=utf-8", `{"Name":"p1","Count":30,"Annotations":{"a":"b"}}`, true},
+		{"application/x-www-form-urlencoded", `{"Name":"p1","Count":30,"Annotations":{"a":"b"}}`, true},
+		{"application/msgpack", "\x83\xa4Name\xa2p1\xa5Count\x1e\xabAnnotations\x81\xa1a\xa1b", true},
+		{"text/plain", `{"Name":"p1","Count":30,"Annotations":{"a":"b"}}`, true},
+		{"text/plain", "Name: p1", false},
+		{"application/yaml", "Name: [p1", false},
+	}
+
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		r, err := mgr.CreateResourceFromRequest(req)
+		if tc.isValid {
+			ut.Assert(t, err == nil, "create with %s failed %v", tc.contentType, err)
+			pod := r.(*Pod)
+			ut.Equal(t, pod.Name, "p1")
+			ut.Equal(t, pod.Count, uint32(30))
+			ut.Equal(t, pod.Annotations, map[string]string{"a": "b"})
+		} else {
+			ut.Assert(t, err != nil, "create with %s should fail", tc.contentType)
+		}
+	}
+}
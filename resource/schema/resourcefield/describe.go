@@ -0,0 +1,124 @@
+package resourcefield
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//FieldDescription describes field in json form, elem is set for
+//array and map, fields is set for struct
+type FieldDescription struct {
+	Name        string                 `json:"name,omitempty"`
+	Type        string                 `json:"type"`
+	Constraints map[string]interface{} `json:"constraints,omitempty"`
+	Elem        *FieldDescription      `json:"elem,omitempty"`
+	Fields      []FieldDescription     `json:"fields,omitempty"`
+}
+
+//constraints come from the fields built with rest tag, fields without
+//rest tag are described too
+func Describe(typ reflect.Type) ([]FieldDescription, error) {
+	sf, err := NewBuilder().Build(typ)
+	if err != nil {
+		return nil, err
+	}
+	return describeFields(typ, sf), nil
+}
+
+func describeFields(typ reflect.Type, sf *structField) []FieldDescription {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var descs []FieldDescription
+	for i := 0; i < typ.NumField(); i++ {
+		ft := typ.Field(i)
+		if ft.PkgPath != "" {
+			continue
+		}
+
+		tag := ft.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		//builder collects fields of embedded struct into the outer struct
+		if ft.Anonymous && strings.Split(tag, ",")[0] == "" {
+			descs = append(descs, describeFields(ft.Type, sf)...)
+			continue
+		}
+
+		var field Field
+		if sf != nil {
+			field = sf.fields[ft.Name]
+		}
+		descs = append(descs, describeField(fieldJsonName(ft.Name, tag), ft.Type, field))
+	}
+	return descs
+}
+
+func describeField(name string, typ reflect.Type, field Field) FieldDescription {
+	desc := FieldDescription{Name: name}
+	if field != nil {
+		desc.Constraints = field.Describe()
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	desc.Type = typeName(typ)
+	switch desc.Type {
+	case "array", "map":
+		elem := describeField("", typ.Elem(), innerField(field))
+		desc.Elem = &elem
+	case "struct":
+		sf, _ := field.(*structField)
+		desc.Fields = describeFields(typ, sf)
+	}
+	return desc
+}
+
+//struct field of slice and map elem
+func innerField(field Field) Field {
+	var inner Field
+	switch f := field.(type) {
+	case *sliceStructField:
+		inner = f.inner
+	case *mapStructField:
+		inner = f.inner
+	}
+	if sf, ok := inner.(*structField); ok && sf != nil {
+		return sf
+	}
+	return nil
+}
+
+func typeName(typ reflect.Type) string {
+	if typ.Kind() == reflect.Struct && typ.ConvertibleTo(timeType) {
+		return "time"
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "map"
+	case reflect.Struct:
+		return "struct"
+	default:
+		return "any"
+	}
+}
@@ -0,0 +1,56 @@
+package resourcefield
+
+import (
+	"reflect"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestDescribe(t *testing.T) {
+	descs, err := Describe(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+
+	fields := make(map[string]FieldDescription)
+	for _, desc := range descs {
+		fields[desc.Name] = desc
+	}
+	ut.Equal(t, len(fields), 17)
+
+	ut.Equal(t, fields["Id"].Type, "string")
+	ut.Assert(t, fields["stringWithDefault"].Constraints == nil, "")
+	ut.Equal(t, fields["name"].Constraints, map[string]interface{}{"required": true})
+	ut.Equal(t, fields["stringWithOption"].Constraints, map[string]interface{}{
+		"required": true,
+		"options":  []string{"lvm", "ceph"},
+	})
+	ut.Equal(t, fields["stringWithLenLimit"].Constraints, map[string]interface{}{
+		"minLen":          int64(2),
+		"maxLen":          int64(10),
+		"exclusiveMaxLen": true,
+	})
+	ut.Equal(t, fields["intWithRange"].Type, "uint")
+
+	intSlice := fields["intSlice"]
+	ut.Equal(t, intSlice.Type, "array")
+	ut.Equal(t, intSlice.Elem.Type, "uint")
+
+	for _, name := range []string{"sliceComposition", "stringMapComposition", "slicePtrComposition", "stringPtrMapComposition"} {
+		elem := fields[name].Elem
+		ut.Equal(t, fields[name].Constraints, map[string]interface{}{"required": true})
+		ut.Equal(t, elem.Type, "struct")
+		ut.Equal(t, len(elem.Fields), 2)
+		ut.Equal(t, elem.Fields[0].Constraints, map[string]interface{}{
+			"min":          int64(1),
+			"max":          int64(20),
+			"exclusiveMax": true,
+		})
+	}
+
+	ptr := fields["ptrComposition"]
+	ut.Equal(t, ptr.Type, "struct")
+	ut.Equal(t, ptr.Constraints, map[string]interface{}{"required": true})
+	ut.Equal(t, ptr.Fields[0].Name, "int8WithRange")
+	ut.Equal(t, ptr.Fields[0].Type, "int")
+	ut.Assert(t, ptr.Fields[1].Constraints == nil, "")
+}
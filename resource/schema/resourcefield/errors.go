@@ -0,0 +1,64 @@
+package resourcefield
+
+import (
+	"fmt"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+//violation of field, path is json pointer of the field
+type FieldError struct {
+	Path    string
+	Code    goresterr.ErrorCode
+	Params  map[string]interface{}
+	Message string
+}
+
+type FieldErrors []*FieldError
+
+func (es *FieldErrors) add(path string, code goresterr.ErrorCode, params map[string]interface{}, format string, args ...interface{}) {
+	*es = append(*es, &FieldError{
+		Path:    path,
+		Code:    code,
+		Params:  params,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (es FieldErrors) Error() string {
+	messages := make([]string, 0, len(es))
+	for _, e := range es {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (es FieldErrors) Details() []goresterr.ErrorDetail {
+	details := make([]goresterr.ErrorDetail, 0, len(es))
+	for _, e := range es {
+		details = append(details, goresterr.ErrorDetail{
+			Code:    e.Code.Code,
+			Field:   e.Path,
+			Params:  e.Params,
+			Message: e.Message,
+		})
+	}
+	return details
+}
+
+func validateField(f Field, val interface{}, raw map[string]interface{}) error {
+	var errs FieldErrors
+	f.validate(val, raw, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+//append token to json pointer
+func JoinPath(path, token string) string {
+	return path + "/" + pointerEscaper.Replace(token)
+}
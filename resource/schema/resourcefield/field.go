@@ -1,9 +1,11 @@
 package resourcefield
 
 import (
-	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 
+	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/resource/schema/resourcefield/validator"
 )
 
@@ -16,7 +18,14 @@ type Field interface {
 
 	//validate fields of go struct
 	//resource should be unmarshalled from raw
+	//FieldErrors is returned which includes all the violations
 	Validate(resource interface{}, raw map[string]interface{}) error
+	//path is json pointer of the object which includes the field
+	validate(resource interface{}, raw map[string]interface{}, path string, errs *FieldErrors)
+
+	//constraints of the field like required and validators,
+	//nil is returned if field has no constraint
+	Describe() map[string]interface{}
 }
 
 var _ Field = &leafField{}
@@ -65,28 +74,53 @@ func (f *leafField) SetValidators(validators []validator.Validator) {
 }
 
 func (f *leafField) Validate(val interface{}, raw map[string]interface{}) error {
+	return validateField(f, val, raw)
+}
+
+func (f *leafField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
+	path = JoinPath(path, f.jsonName)
 	if _, ok := raw[f.JsonName()]; !ok {
 		if f.IsRequired() {
-			return fmt.Errorf("field %s is missing", f.jsonName)
-		} else {
-			return nil
+			errs.add(path, goresterr.MissingRequired, nil, "field %s is missing", path)
 		}
+		return
 	}
 
 	if reflect.ValueOf(val).Kind() != f.kind {
-		return fmt.Errorf("field %s has invalid invalid kind", f.jsonName)
+		errs.add(path, goresterr.InvalidType, nil, "field %s has invalid kind", path)
+		return
 	}
 
-	return f.doValidate(val)
+	f.doValidate(val, path, errs)
 }
 
-func (f *leafField) doValidate(val interface{}) error {
+func (f *leafField) Describe() map[string]interface{} {
+	var constraints map[string]interface{}
+	if f.required {
+		constraints = map[string]interface{}{"required": true}
+	}
 	for _, validator := range f.validators {
-		if err := validator.Validate(val); err != nil {
-			return err
+		if constraints == nil {
+			constraints = make(map[string]interface{})
+		}
+		for k, v := range validator.Describe() {
+			constraints[k] = v
+		}
+	}
+	return constraints
+}
+
+//all the validators are checked
+func (f *leafField) doValidate(val interface{}, path string, errs *FieldErrors) {
+	for _, v := range f.validators {
+		if err := v.Validate(val); err != nil {
+			if verr, ok := err.(*validator.Error); ok {
+				errs.add(path, verr.Code, verr.Params, "field %s is invalid: %s", path, verr.Message)
+			} else {
+				errs.add(path, goresterr.InvalidFormat, nil, "field %s is invalid: %s", path, err.Error())
+			}
 		}
 	}
-	return nil
 }
 
 type sliceLeafField struct {
@@ -100,27 +134,29 @@ func newSliceLeafField(inner *leafField) *sliceLeafField {
 }
 
 func (f *sliceLeafField) Validate(val interface{}, raw map[string]interface{}) error {
-	specified, _, err := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Slice)
-	if err != nil {
-		return err
+	return validateField(f, val, raw)
+}
+
+func (f *sliceLeafField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
+	path = JoinPath(path, f.JsonName())
+	specified, _, ok := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Slice, path, errs)
+	if !ok || !specified {
+		return
 	}
 
 	value := reflect.ValueOf(val)
 	if value.Kind() != reflect.Slice {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+		errs.add(path, goresterr.InvalidType, nil, "runtime value of %s isn't synchronize with json data", path)
+		return
 	}
-	if specified {
-		for i := 0; i < value.Len(); i++ {
-			if err := f.leafField.doValidate(value.Index(i).Interface()); err != nil {
-				return err
-			}
-		}
+	for i := 0; i < value.Len(); i++ {
+		f.leafField.doValidate(value.Index(i).Interface(), JoinPath(path, strconv.Itoa(i)), errs)
 	}
-	return nil
 }
 
-func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.Kind) (bool, interface{}, error) {
-	jsonVal, specified := raw[f.JsonName()]
+//ok is false if the json value is invalid
+func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.Kind, path string, errs *FieldErrors) (specified bool, jsonVal interface{}, ok bool) {
+	jsonVal, specified = raw[f.JsonName()]
 	//handle set direct name to nil, which is same with not speicified
 	if jsonVal == nil {
 		specified = false
@@ -128,25 +164,24 @@ func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.
 
 	if f.IsRequired() {
 		if !specified {
-			return specified, nil, fmt.Errorf("field %s is missing", f.JsonName())
+			errs.add(path, goresterr.MissingRequired, nil, "field %s is missing", path)
+			return specified, nil, false
 		}
 	}
 
 	if specified {
 		v := reflect.ValueOf(jsonVal)
-		if !v.IsValid() {
-			return specified, nil, fmt.Errorf("field %s has invalid value", f.JsonName())
-		}
-
 		if v.Kind() != kind {
-			return specified, nil, fmt.Errorf("field %s isn't %v", f.JsonName(), kind)
+			errs.add(path, goresterr.InvalidType, nil, "field %s isn't %v", path, kind)
+			return specified, nil, false
 		}
 
 		if v.Len() == 0 && f.IsRequired() {
-			return specified, nil, fmt.Errorf("field %s with empty slice ", f.JsonName())
+			errs.add(path, goresterr.MissingRequired, nil, "field %s is empty", path)
+			return specified, nil, false
 		}
 	}
-	return specified, jsonVal, nil
+	return specified, jsonVal, true
 }
 
 type sliceStructField struct {
@@ -162,33 +197,32 @@ func newSliceStructField(self, inner Field) *sliceStructField {
 }
 
 func (f *sliceStructField) Validate(val interface{}, raw map[string]interface{}) error {
-	specified, jsonVal, err := fieldIsSpecifiedWithKind(f.Field, raw, reflect.Slice)
-	if err != nil {
-		return err
-	}
+	return validateField(f, val, raw)
+}
 
-	if !specified || f.inner == nil {
-		return nil
+func (f *sliceStructField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
+	path = JoinPath(path, f.Field.JsonName())
+	specified, jsonVal, ok := fieldIsSpecifiedWithKind(f.Field, raw, reflect.Slice, path, errs)
+	if !ok || !specified || f.inner == nil {
+		return
 	}
 
 	value := reflect.ValueOf(val)
 	jsonValue := reflect.ValueOf(jsonVal)
 	if value.Kind() != reflect.Slice || value.Len() != jsonValue.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+		errs.add(path, goresterr.InvalidType, nil, "runtime value of %s isn't synchronize with json data", path)
+		return
 	}
 
 	for i := 0; i < value.Len(); i++ {
-		elemVal := jsonValue.Index(i).Interface()
-		elemRaw, ok := elemVal.(map[string]interface{})
+		elemPath := JoinPath(path, strconv.Itoa(i))
+		elemRaw, ok := jsonValue.Index(i).Interface().(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("elem of field %s is not a struct", f.Field.JsonName())
-		}
-		if err := f.inner.Validate(value.Index(i).Interface(), elemRaw); err != nil {
-			return err
+			errs.add(elemPath, goresterr.InvalidType, nil, "elem %s is not a struct", elemPath)
+			continue
 		}
+		f.inner.validate(value.Index(i).Interface(), elemRaw, elemPath, errs)
 	}
-
-	return nil
 }
 
 type mapLeafField struct {
@@ -202,25 +236,33 @@ func newMapLeafField(inner *leafField) *mapLeafField {
 }
 
 func (f *mapLeafField) Validate(val interface{}, raw map[string]interface{}) error {
-	specified, _, err := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Map)
-	if err != nil {
-		return err
-	}
-	if !specified {
-		return nil
+	return validateField(f, val, raw)
+}
+
+func (f *mapLeafField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
+	path = JoinPath(path, f.JsonName())
+	specified, _, ok := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Map, path, errs)
+	if !ok || !specified {
+		return
 	}
 
 	value := reflect.ValueOf(val)
 	if value.Kind() != reflect.Map {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+		errs.add(path, goresterr.InvalidType, nil, "runtime value of %s isn't synchronize with json data", path)
+		return
 	}
-	iter := value.MapRange()
-	for iter.Next() {
-		if err := f.leafField.doValidate(iter.Value().Interface()); err != nil {
-			return err
-		}
+	for _, key := range sortedMapKeys(value) {
+		f.leafField.doValidate(value.MapIndex(key).Interface(), JoinPath(path, key.String()), errs)
 	}
-	return nil
+}
+
+//sort keys to report errors in stable order
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
 }
 
 type mapStructField struct {
@@ -236,33 +278,33 @@ func newMapStructField(self, inner Field) *mapStructField {
 }
 
 func (f *mapStructField) Validate(val interface{}, raw map[string]interface{}) error {
-	specified, jsonVal, err := fieldIsSpecifiedWithKind(f.Field, raw, reflect.Map)
-	if err != nil {
-		return err
-	}
+	return validateField(f, val, raw)
+}
 
-	if !specified || f.inner == nil {
-		return nil
+func (f *mapStructField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
+	path = JoinPath(path, f.Field.JsonName())
+	specified, jsonVal, ok := fieldIsSpecifiedWithKind(f.Field, raw, reflect.Map, path, errs)
+	if !ok || !specified || f.inner == nil {
+		return
 	}
 
-	jsonValue := reflect.ValueOf(jsonVal)
+	jsonMap := jsonVal.(map[string]interface{})
 	value := reflect.ValueOf(val)
-	if value.Kind() != reflect.Map || jsonValue.Len() != value.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+	if value.Kind() != reflect.Map || len(jsonMap) != value.Len() {
+		errs.add(path, goresterr.InvalidType, nil, "runtime value of %s isn't synchronize with json data", path)
+		return
 	}
 
-	ji := jsonValue.MapRange()
-	vi := value.MapRange()
-	for ji.Next() && vi.Next() {
-		elemRaw, ok := (ji.Value().Interface()).(map[string]interface{})
+	//json value is looked up by key, map iteration order is random
+	for _, key := range sortedMapKeys(value) {
+		elemPath := JoinPath(path, key.String())
+		elemRaw, ok := jsonMap[key.String()].(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("value of field %s is not a struct", f.Field.JsonName())
-		}
-		if err := f.inner.Validate(vi.Value().Interface(), elemRaw); err != nil {
-			return err
+			errs.add(elemPath, goresterr.InvalidType, nil, "value %s is not a struct", elemPath)
+			continue
 		}
+		f.inner.validate(value.MapIndex(key).Interface(), elemRaw, elemPath, errs)
 	}
-	return nil
 }
 
 type structField struct {
@@ -277,35 +319,55 @@ func newStructField(self Field, fields map[string]Field) *structField {
 	}
 }
 
-func (f *structField) Validate(val interface{}, raw map[string]interface{}) error {
-	value := reflect.ValueOf(val)
-	//only handle one level redirect
-	if value.Kind() == reflect.Ptr {
-		value = value.Elem()
-	}
-	if value.Kind() != reflect.Struct {
-		return fmt.Errorf("struct field with non-sturct but %v", value.Kind())
+//top level struct has no self field
+func (f *structField) Describe() map[string]interface{} {
+	if f.Field == nil {
+		return nil
 	}
+	return f.Field.Describe()
+}
+
+func (f *structField) Validate(val interface{}, raw map[string]interface{}) error {
+	return validateField(f, val, raw)
+}
 
+func (f *structField) validate(val interface{}, raw map[string]interface{}, path string, errs *FieldErrors) {
 	//this is a nest struct
 	if f.Field != nil {
 		jsonName := f.Field.JsonName()
+		path = JoinPath(path, jsonName)
 		jsonVal, ok := raw[jsonName]
 		if f.Field.IsRequired() && !ok {
-			return fmt.Errorf("struct field %s is missing", jsonName)
+			errs.add(path, goresterr.MissingRequired, nil, "struct field %s is missing", path)
+			return
 		}
 		//field isn't speicifed
 		if !ok {
-			return nil
+			return
 		}
 
 		if nr, ok := jsonVal.(map[string]interface{}); ok {
 			raw = nr
 		} else {
-			return fmt.Errorf("value of field %s in json data is not a struct", jsonName)
+			errs.add(path, goresterr.InvalidType, nil, "value of field %s in json data is not a struct", path)
+			return
 		}
 	}
 
+	f.validateFields(reflect.ValueOf(val), raw, path, errs)
+}
+
+//fields of embedded struct are validated against same json object
+func (f *structField) validateFields(value reflect.Value, raw map[string]interface{}, path string, errs *FieldErrors) {
+	//only handle one level redirect
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		errs.add(path, goresterr.InvalidType, nil, "struct field %s with non-sturct but %v", path, value.Kind())
+		return
+	}
+
 	typ := value.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		ft := typ.Field(i)
@@ -315,17 +377,12 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 		}
 
 		if ft.Anonymous {
-			if err := f.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
-			}
+			f.validateFields(value.Field(i), raw, path, errs)
 			continue
 		}
 
 		if field, ok := f.fields[ft.Name]; ok {
-			if err := field.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
-			}
+			field.validate(value.Field(i).Interface(), raw, path, errs)
 		}
 	}
-	return nil
 }
@@ -341,6 +341,62 @@ func TestValidateWithOptionalField(t *testing.T) {
 	ut.Assert(t, err == nil, "shouldn't get err %v", err)
 }
 
+func TestValidateCollectAllErrors(t *testing.T) {
+	type Node struct {
+		Address string `json:"address" rest:"required=true,minLen=7,maxLen=15"`
+	}
+
+	type MyOption string
+
+	type TestStruct struct {
+		Name    string              `json:"name" rest:"required=true"`
+		Storage MyOption            `json:"storage" rest:"options=lvm|ceph"`
+		Nodes   []Node              `json:"nodes" rest:"required=true"`
+		Ports   map[string]int32    `json:"ports" rest:"min=1,max=65536"`
+		Disks   map[string]*Node    `json:"disks"`
+		Labels  map[string]MyOption `json:"labels" rest:"options=lvm|ceph"`
+	}
+
+	builder := NewBuilder()
+	sf, err := builder.Build(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+
+	ts := TestStruct{
+		Storage: "nfs",
+		Nodes:   []Node{{Address: "1.1.1.1"}, {Address: "1.1.1.2"}, {Address: "1.1"}},
+		Ports:   map[string]int32{"http": 80, "https": 0},
+		Disks:   map[string]*Node{"a/b": {Address: "10.0.0.1.10.0.0.1"}},
+	}
+	rawByte, _ := json.Marshal(ts)
+	raw := make(map[string]interface{})
+	json.Unmarshal(rawByte, &raw)
+	delete(raw, "name")
+	err = sf.Validate(ts, raw)
+	errs, ok := err.(FieldErrors)
+	ut.Assert(t, ok, "should return field errors but get %v", err)
+
+	expects := []struct {
+		path string
+		code string
+	}{
+		{"/name", "MissingRequired"},
+		{"/storage", "InvalidOption"},
+		{"/nodes/2/address", "MinLengthExceeded"},
+		{"/ports/https", "MinLimitExceeded"},
+		{"/disks/a~1b/address", "MaxLengthExceeded"},
+	}
+	ut.Equal(t, len(errs), len(expects))
+	details := errs.Details()
+	for i, expect := range expects {
+		ut.Equal(t, details[i].Field, expect.path)
+		ut.Equal(t, details[i].Code, expect.code)
+		ut.Assert(t, strings.Contains(details[i].Message, expect.path), "message %s should include path", details[i].Message)
+	}
+	ut.Equal(t, details[2].Params["minLen"], int64(7))
+	ut.Equal(t, details[3].Params["min"], int64(1))
+	ut.Assert(t, strings.Contains(err.Error(), "; "), "")
+}
+
 func makeSureValidateFailedWithInfo(t *testing.T, sf Field, structVal interface{}, errorInfo string) {
 	rawByte, _ := json.Marshal(structVal)
 	raw := make(map[string]interface{})
@@ -0,0 +1,119 @@
+package resourcefield
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//FieldPath locates field of go struct by json names like "status.phase"
+type FieldPath struct {
+	path    string
+	indexes [][]int
+	typ     reflect.Type
+}
+
+func LookupField(typ reflect.Type, path string) (*FieldPath, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty field name")
+	}
+
+	fp := &FieldPath{
+		path: path,
+	}
+	for _, name := range strings.Split(path, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("field %s doesn't exist", path)
+		}
+
+		sf, ok := lookupJsonField(typ, name)
+		if ok == false {
+			return nil, fmt.Errorf("field %s doesn't exist", path)
+		}
+		fp.indexes = append(fp.indexes, sf.Index)
+		typ = sf.Type
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	fp.typ = typ
+	return fp, nil
+}
+
+func lookupJsonField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	return findJsonField(typ, func(jsonName string) bool {
+		return jsonName == name
+	})
+}
+
+//embedded struct without json name is inlined
+func findJsonField(typ reflect.Type, match func(string) bool) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && strings.Split(tag, ",")[0] == "" {
+			et := sf.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				if inner, ok := findJsonField(et, match); ok {
+					inner.Index = append([]int{i}, inner.Index...)
+					return inner, true
+				}
+				continue
+			}
+		}
+
+		if sf.PkgPath == "" && match(fieldJsonName(sf.Name, tag)) {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func (p *FieldPath) Path() string {
+	return p.path
+}
+
+//type of the field, pointer is dereferenced
+func (p *FieldPath) Type() reflect.Type {
+	return p.typ
+}
+
+//return false if any pointer in the path is nil
+func (p *FieldPath) Value(v reflect.Value) (reflect.Value, bool) {
+	for _, index := range p.indexes {
+		if v = indirect(v); v.IsValid() == false {
+			return v, false
+		}
+
+		var err error
+		if v, err = v.FieldByIndexErr(index); err != nil {
+			return v, false
+		}
+	}
+
+	if v = indirect(v); v.IsValid() == false {
+		return v, false
+	}
+	return v, true
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,46 @@
+package resourcefield
+
+import (
+	"reflect"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestLookupField(t *testing.T) {
+	typ := reflect.TypeOf(TestStruct{})
+	s := &TestStruct{
+		Embed:          Embed{Id: "x"},
+		Name:           "n",
+		PtrComposition: &IncludeStruct{Int8WithRange: 5},
+	}
+
+	cases := []struct {
+		path  string
+		kind  reflect.Kind
+		value interface{}
+	}{
+		{"Id", reflect.String, "x"},
+		{"name", reflect.String, "n"},
+		{"ptrComposition.int8WithRange", reflect.Int8, int8(5)},
+		{"ptrComposition", reflect.Struct, IncludeStruct{Int8WithRange: 5}},
+	}
+	for _, tc := range cases {
+		fp, err := LookupField(typ, tc.path)
+		ut.Assert(t, err == nil, "lookup %s failed %v", tc.path, err)
+		ut.Equal(t, fp.Type().Kind(), tc.kind)
+		v, ok := fp.Value(reflect.ValueOf(s))
+		ut.Assert(t, ok, "")
+		ut.Equal(t, v.Interface(), tc.value)
+	}
+
+	for _, path := range []string{"", "Name", "unknown", "name.a", "ptrComposition.Int8WithRange"} {
+		_, err := LookupField(typ, path)
+		ut.Assert(t, err != nil, "lookup %s should fail", path)
+	}
+
+	s.PtrComposition = nil
+	fp, _ := LookupField(typ, "ptrComposition.int8WithRange")
+	_, ok := fp.Value(reflect.ValueOf(s))
+	ut.Assert(t, ok == false, "")
+}
@@ -0,0 +1,97 @@
+package resourcefield
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const (
+	sensitiveTag  = "sensitive=true"
+	RedactedValue = "******"
+)
+
+//return json object of v in which the value of fields with rest tag
+//sensitive=true is replaced with RedactedValue, fields in nested struct,
+//slice and map are redacted too
+func Redact(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	if v != nil {
+		redact(reflect.TypeOf(v), obj)
+	}
+	return obj, nil
+}
+
+func redact(typ reflect.Type, obj interface{}) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if m, ok := obj.(map[string]interface{}); ok {
+			redactStruct(typ, m)
+		}
+	case reflect.Slice, reflect.Array:
+		if elems, ok := obj.([]interface{}); ok {
+			for _, elem := range elems {
+				redact(typ.Elem(), elem)
+			}
+		}
+	case reflect.Map:
+		if m, ok := obj.(map[string]interface{}); ok {
+			for _, elem := range m {
+				redact(typ.Elem(), elem)
+			}
+		}
+	}
+}
+
+func redactStruct(typ reflect.Type, obj map[string]interface{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && strings.Split(tag, ",")[0] == "" {
+			redact(sf.Type, obj)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := fieldJsonName(sf.Name, tag)
+		value, ok := obj[name]
+		if ok == false {
+			continue
+		}
+
+		if isSensitive(sf.Tag.Get("rest")) {
+			obj[name] = RedactedValue
+		} else {
+			redact(sf.Type, value)
+		}
+	}
+}
+
+func isSensitive(rest string) bool {
+	for _, tag := range strings.Split(rest, ",") {
+		if tag == sensitiveTag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package resourcefield
+
+import (
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+type credential struct {
+	User     string `json:"user"`
+	Password string `json:"password" rest:"sensitive=true"`
+}
+
+type account struct {
+	Name        string                 `json:"name" rest:"required=true,sensitive=true"`
+	Credentials []credential           `json:"credentials"`
+	Backup      *credential            `json:"backup,omitempty"`
+	Keys        map[string]*credential `json:"keys"`
+}
+
+func TestRedact(t *testing.T) {
+	a := &account{
+		Name:        "ben",
+		Credentials: []credential{{User: "u1", Password: "p1"}},
+		Keys:        map[string]*credential{"k": {User: "u2", Password: "p2"}},
+	}
+	obj, err := Redact(a)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, obj, map[string]interface{}{
+		"name": RedactedValue,
+		"credentials": []interface{}{
+			map[string]interface{}{"user": "u1", "password": RedactedValue},
+		},
+		"keys": map[string]interface{}{
+			"k": map[string]interface{}{"user": "u2", "password": RedactedValue},
+		},
+	})
+	ut.Equal(t, a.Credentials[0].Password, "p1")
+
+	obj, err = Redact(nil)
+	ut.Assert(t, err == nil && obj == nil, "")
+}
@@ -0,0 +1,231 @@
+package resourcefield
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+const (
+	JSONString  = "string"
+	JSONInteger = "integer"
+	JSONNumber  = "number"
+	JSONBoolean = "boolean"
+	JSONArray   = "array"
+	JSONObject  = "object"
+	JSONNull    = "null"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+//check json value decoded by encoding/json against go type, value with
+//wrong type is reported as InvalidType with expected and received type,
+//field which doesn't exist in go struct is reported as UnknownField
+//if strict is true, FieldErrors is returned which includes all the errors.
+//number should be decoded as json.Number by decoder with UseNumber, so
+//large integer is checked without precision loss, float64 is also accepted
+func CheckJSONType(typ reflect.Type, raw interface{}, strict bool) error {
+	var errs FieldErrors
+	checkJSONType(typ, raw, "", strict, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkJSONType(typ reflect.Type, raw interface{}, path string, strict bool, errs *FieldErrors) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	//null is ignored by encoding/json
+	if raw == nil || typ.Kind() == reflect.Interface {
+		return
+	}
+
+	pt := reflect.PtrTo(typ)
+	if pt.Implements(jsonUnmarshalerType) {
+		return
+	}
+	if pt.Implements(textUnmarshalerType) {
+		if _, ok := raw.(string); !ok {
+			addTypeError(errs, path, JSONString, raw)
+		}
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			addTypeError(errs, path, JSONString, raw)
+		}
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			addTypeError(errs, path, JSONBoolean, raw)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInt(typ, raw) {
+			addTypeError(errs, path, JSONInteger, raw)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isUint(typ, raw) {
+			addTypeError(errs, path, JSONInteger, raw)
+		}
+	case reflect.Float32, reflect.Float64:
+		if !isFloat(typ, raw) {
+			addTypeError(errs, path, JSONNumber, raw)
+		}
+	case reflect.Slice, reflect.Array:
+		//[]byte is encoded as base64 string
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			if _, ok := raw.(string); !ok {
+				addTypeError(errs, path, JSONString, raw)
+			}
+			return
+		}
+		elems, ok := raw.([]interface{})
+		if !ok {
+			addTypeError(errs, path, JSONArray, raw)
+			return
+		}
+		for i, elem := range elems {
+			checkJSONType(typ.Elem(), elem, JoinPath(path, strconv.Itoa(i)), strict, errs)
+		}
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			addTypeError(errs, path, JSONObject, raw)
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			checkJSONType(typ.Elem(), obj[key], JoinPath(path, key), strict, errs)
+		}
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			addTypeError(errs, path, JSONObject, raw)
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			fieldPath := JoinPath(path, key)
+			sf, ok := lookupJsonFieldFold(typ, key)
+			if !ok {
+				if strict {
+					errs.add(fieldPath, goresterr.UnknownField, nil, "field %s is unknown", fieldPath)
+				}
+				continue
+			}
+			checkJSONType(sf.Type, obj[key], fieldPath, strict, errs)
+		}
+	}
+}
+
+//json.Number is parsed like encoding/json, so 1.0 and 1e2 aren't integer
+func isInt(typ reflect.Type, raw interface{}) bool {
+	var i int64
+	switch v := raw.(type) {
+	case json.Number:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return false
+		}
+		i = n
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return false
+		}
+		i = int64(v)
+	default:
+		return false
+	}
+	return !reflect.New(typ).Elem().OverflowInt(i)
+}
+
+func isUint(typ reflect.Type, raw interface{}) bool {
+	var u uint64
+	switch v := raw.(type) {
+	case json.Number:
+		n, err := strconv.ParseUint(string(v), 10, 64)
+		if err != nil {
+			return false
+		}
+		u = n
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return false
+		}
+		u = uint64(v)
+	default:
+		return false
+	}
+	return !reflect.New(typ).Elem().OverflowUint(u)
+}
+
+func isFloat(typ reflect.Type, raw interface{}) bool {
+	var f float64
+	switch v := raw.(type) {
+	case json.Number:
+		n, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return false
+		}
+		f = n
+	case float64:
+		f = v
+	default:
+		return false
+	}
+	return !reflect.New(typ).Elem().OverflowFloat(f)
+}
+
+//exact match is preferred, then case-insensitive match like encoding/json
+func lookupJsonFieldFold(typ reflect.Type, name string) (reflect.StructField, bool) {
+	if sf, ok := lookupJsonField(typ, name); ok {
+		return sf, true
+	}
+	return findJsonField(typ, func(jsonName string) bool {
+		return strings.EqualFold(jsonName, name)
+	})
+}
+
+func addTypeError(errs *FieldErrors, path string, expected string, raw interface{}) {
+	received := JSONValueType(raw)
+	errs.add(path, goresterr.InvalidType, map[string]interface{}{"expected": expected, "received": received},
+		"field %s should be %s but get %s", path, expected, received)
+}
+
+//type of value decoded by encoding/json
+func JSONValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return JSONNull
+	case string:
+		return JSONString
+	case bool:
+		return JSONBoolean
+	case float64, json.Number:
+		return JSONNumber
+	case []interface{}:
+		return JSONArray
+	default:
+		return JSONObject
+	}
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
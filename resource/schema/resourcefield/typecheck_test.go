@@ -0,0 +1,89 @@
+package resourcefield
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestCheckJSONType(t *testing.T) {
+	type Node struct {
+		Address string `json:"address"`
+		Port    uint16 `json:"port"`
+	}
+
+	type Cluster struct {
+		Node      `json:",inline"`
+		NodeCount int              `json:"nodeCount"`
+		Ratio     float32          `json:"ratio"`
+		Enabled   bool             `json:"enabled"`
+		Nodes     []*Node          `json:"nodes"`
+		Labels    map[string]int8  `json:"labels"`
+		Data      []byte           `json:"data"`
+		Created   time.Time        `json:"created"`
+		Extra     interface{}      `json:"extra"`
+		Groups    map[string][]int `json:"groups,omitempty"`
+		Size      int64            `json:"size"`
+		Capacity  uint64           `json:"capacity"`
+	}
+
+	cases := []struct {
+		body     string
+		strict   bool
+		paths    []string
+		expected []string
+		received []string
+	}{
+		{`{"address":"1.1.1.1","port":80,"nodeCount":5,"ratio":0.5,"enabled":true,"nodes":[{"address":"2.2.2.2"},null],"labels":{"a":1},"data":"YQ==","created":"2020-01-01T00:00:00Z","extra":[1]}`, true, nil, nil, nil},
+		{`{"NodeCount":5,"unknown":1}`, false, nil, nil, nil},
+		{`{"nodeCount":"five"}`, false, []string{"/nodeCount"}, []string{JSONInteger}, []string{JSONString}},
+		{`{"nodeCount":1.5,"port":-1,"labels":{"a":200}}`, false, []string{"/labels/a", "/nodeCount", "/port"}, []string{JSONInteger, JSONInteger, JSONInteger}, []string{JSONNumber, JSONNumber, JSONNumber}},
+		{`{"nodes":[{"address":"1.1.1.1"},{"address":1}],"enabled":"true"}`, false, []string{"/enabled", "/nodes/1/address"}, []string{JSONBoolean, JSONString}, []string{JSONString, JSONNumber}},
+		{`{"nodes":{},"groups":{"a/b":[1,"2"]},"data":[1]}`, false, []string{"/data", "/groups/a~1b/1", "/nodes"}, []string{JSONString, JSONInteger, JSONArray}, []string{JSONArray, JSONString, JSONObject}},
+		{`{"nodeCount":5,"unknown":1,"nodes":[{"host":"a"}]}`, true, []string{"/nodes/0/host", "/unknown"}, nil, nil},
+		{`{"size":9223372036854775807,"capacity":18446744073709551615,"ratio":1e10}`, false, nil, nil, nil},
+		{`{"size":9223372036854775808,"capacity":-1,"ratio":1e39,"nodeCount":1.0}`, false, []string{"/capacity", "/nodeCount", "/ratio", "/size"}, []string{JSONInteger, JSONInteger, JSONNumber, JSONInteger}, []string{JSONNumber, JSONNumber, JSONNumber, JSONNumber}},
+	}
+
+	for _, tc := range cases {
+		var raw interface{}
+		d := json.NewDecoder(strings.NewReader(tc.body))
+		d.UseNumber()
+		ut.Assert(t, d.Decode(&raw) == nil, "")
+		err := CheckJSONType(reflect.TypeOf(&Cluster{}), raw, tc.strict)
+		if len(tc.paths) == 0 {
+			ut.Assert(t, err == nil, "%s should be valid but get %v", tc.body, err)
+			continue
+		}
+
+		errs, ok := err.(FieldErrors)
+		ut.Assert(t, ok, "%s should be invalid", tc.body)
+		ut.Equal(t, len(errs), len(tc.paths))
+		for i, e := range errs {
+			ut.Equal(t, e.Path, tc.paths[i])
+			if tc.expected == nil {
+				ut.Equal(t, e.Code.Code, "UnknownField")
+			} else {
+				ut.Equal(t, e.Code.Code, "InvalidType")
+				ut.Equal(t, e.Params["expected"], tc.expected[i])
+				ut.Equal(t, e.Params["received"], tc.received[i])
+			}
+		}
+	}
+}
+
+func TestCheckFloatJSONType(t *testing.T) {
+	type Node struct {
+		Port uint16 `json:"port"`
+	}
+
+	var raw interface{}
+	json.Unmarshal([]byte(`{"port":80}`), &raw)
+	ut.Assert(t, CheckJSONType(reflect.TypeOf(&Node{}), raw, false) == nil, "")
+	json.Unmarshal([]byte(`{"port":80.5}`), &raw)
+	ut.Assert(t, CheckJSONType(reflect.TypeOf(&Node{}), raw, false) != nil, "")
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/util"
 )
 
@@ -29,9 +30,12 @@ func (v *domainNameValidator) Validate(val interface{}) error {
 	value := reflect.ValueOf(val)
 	kind := util.Inspect(value.Type())
 	if kind != util.String {
-		return fmt.Errorf("isDomain apply to non-string type: %v", kind)
+		return newError(goresterr.InvalidType, nil, "isDomain apply to non-string type: %v", kind)
 	}
-	return validateDomain(value.String())
+	if err := validateDomain(value.String()); err != nil {
+		return newError(goresterr.InvalidFormat, v.Describe(), "%s", err.Error())
+	}
+	return nil
 }
 
 func validateDomain(s string) error {
@@ -46,6 +50,10 @@ func validateDomain(s string) error {
 	return nil
 }
 
+func (v *domainNameValidator) Describe() map[string]interface{} {
+	return map[string]interface{}{"isDomain": true}
+}
+
 func (b *domainNameValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	for _, tag := range tags {
 		if strings.HasPrefix(tag, domainPrefix) {
@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"fmt"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+//error returned by validator, params are the rule which is violated
+type Error struct {
+	Code    goresterr.ErrorCode
+	Params  map[string]interface{}
+	Message string
+}
+
+func newError(code goresterr.ErrorCode, params map[string]interface{}, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Params:  params,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
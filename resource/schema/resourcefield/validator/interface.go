@@ -7,6 +7,8 @@ import (
 type Validator interface {
 	//validate each field is valid
 	Validate(interface{}) error
+	//constraint in same form with rest tag, like {"min":1, "max":10}
+	Describe() map[string]interface{}
 }
 
 type ValidatorBuilder interface {
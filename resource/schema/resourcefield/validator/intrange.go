@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/util"
 )
 
@@ -34,17 +35,25 @@ func (v *intRangeValidator) Validate(val interface{}) error {
 	case util.Uint:
 		return v.validateValueRange(int64(value.Uint()))
 	default:
-		return fmt.Errorf("int range apply to non-int type:%v", kind)
+		return newError(goresterr.InvalidType, nil, "int range apply to non-int type:%v", kind)
 	}
 }
 
 func (v *intRangeValidator) validateValueRange(i int64) error {
-	if i < v.min || i >= v.max {
-		return fmt.Errorf("int value %v exceed the range limit[%v:%v)", i, v.min, v.max)
+	if i < v.min {
+		return newError(goresterr.MinLimitExceeded, v.Describe(), "int value %v exceed the range limit[%v:%v)", i, v.min, v.max)
+	} else if i >= v.max {
+		return newError(goresterr.MaxLimitExceeded, v.Describe(), "int value %v exceed the range limit[%v:%v)", i, v.min, v.max)
 	}
 	return nil
 }
 
+//max is exclusive like the rest tag, it's explicitly marked, so client
+//won't accept the max value which is rejected by server
+func (v *intRangeValidator) Describe() map[string]interface{} {
+	return map[string]interface{}{"min": v.min, "max": v.max, "exclusiveMax": true}
+}
+
 func (b *intRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	var minStr, maxStr string
 	for _, tag := range tags {
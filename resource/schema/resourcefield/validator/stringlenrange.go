@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/util"
 )
 
@@ -30,19 +31,26 @@ func (v *stringLenRangeValidator) Validate(val interface{}) error {
 	value := reflect.ValueOf(val)
 	kind := util.Inspect(value.Type())
 	if kind != util.String {
-		return fmt.Errorf("stringLen apply to non-string type: %v", kind)
+		return newError(goresterr.InvalidType, nil, "stringLen apply to non-string type: %v", kind)
 	}
 	return v.validateStringLen(value.String())
 }
 
 func (v *stringLenRangeValidator) validateStringLen(s string) error {
 	l := int64(len(s))
-	if l < v.minLen || l >= v.maxLen {
-		return fmt.Errorf("string len %d exceed the range limit[%v:%v)", l, v.minLen, v.maxLen)
+	if l < v.minLen {
+		return newError(goresterr.MinLengthExceeded, v.Describe(), "string len %d exceed the range limit[%v:%v)", l, v.minLen, v.maxLen)
+	} else if l >= v.maxLen {
+		return newError(goresterr.MaxLengthExceeded, v.Describe(), "string len %d exceed the range limit[%v:%v)", l, v.minLen, v.maxLen)
 	}
 	return nil
 }
 
+//maxLen is exclusive like max of int range
+func (v *stringLenRangeValidator) Describe() map[string]interface{} {
+	return map[string]interface{}{"minLen": v.minLen, "maxLen": v.maxLen, "exclusiveMaxLen": true}
+}
+
 func (b *stringLenRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	var minLenStr, maxLenStr string
 	for _, tag := range tags {
@@ -1,11 +1,11 @@
 package validator
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/zdnscloud/cement/slice"
+	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/util"
 )
 
@@ -28,15 +28,19 @@ func (v *optionValidator) Validate(val interface{}) error {
 	value := reflect.ValueOf(val)
 	kind := util.Inspect(value.Type())
 	if kind != util.String {
-		return fmt.Errorf("option apply to non-string type: %v", kind)
+		return newError(goresterr.InvalidType, nil, "option apply to non-string type: %v", kind)
 	}
 	sv := value.String()
 	if slice.SliceIndex(v.options, sv) == -1 {
-		return fmt.Errorf("%s isn't included in options %v", sv, v.options)
+		return newError(goresterr.InvalidOption, v.Describe(), "%s isn't included in options %v", sv, v.options)
 	}
 	return nil
 }
 
+func (v *optionValidator) Describe() map[string]interface{} {
+	return map[string]interface{}{"options": v.options}
+}
+
 func (b *optionValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	for _, tag := range tags {
 		if strings.HasPrefix(tag, optionsTag) {
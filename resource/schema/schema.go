@@ -1,29 +1,34 @@
 package schema
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 
 	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/resource"
 	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 type Schema struct {
 	version          *resource.APIVersion
 	fields           resourcefield.ResourceField
+	descriptions     []resourcefield.FieldDescription
 	actions          []resource.Action
 	handler          resource.Handler
 	resourceKind     resource.ResourceKind
 	resourceName     string
 	resourceKindName string
 	children         []*Schema
+	options          resource.ImportOptions
 }
 
-func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler resource.Handler) (*Schema, error) {
+func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler resource.Handler, opts ...resource.ImportOption) (*Schema, error) {
 	if reflect.ValueOf(kind).Kind() == reflect.Ptr {
 		return nil, fmt.Errorf("resource kind cannot be a pointer")
 	}
@@ -37,13 +42,20 @@ func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler
 		return nil, err
 	}
 
+	descriptions, err := resourcefield.Describe(reflect.TypeOf(kind))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Schema{
 		version:          version,
 		fields:           fields,
+		descriptions:     descriptions,
 		handler:          handler,
 		resourceKind:     kind,
 		resourceName:     resource.DefaultResourceName(kind),
 		resourceKindName: resource.DefaultKindName(kind),
+		options:          resource.NewImportOptions(opts...),
 	}, nil
 }
 
@@ -59,11 +71,51 @@ func (s *Schema) ResourceName() string {
 	return s.resourceName
 }
 
+func (s *Schema) Describe() resource.APIResource {
+	var parents []string
+	for _, parent := range s.resourceKind.GetParents() {
+		parents = append(parents, resource.DefaultKindName(parent))
+	}
+
+	var children []string
+	for _, child := range s.children {
+		children = append(children, child.resourceKindName)
+	}
+
+	return resource.APIResource{
+		Name:              s.resourceName,
+		Kind:              s.resourceKindName,
+		Parents:           parents,
+		Children:          children,
+		CollectionMethods: resource.GetCollectionMethods(s.handler),
+		ResourceMethods:   resource.GetResourceMethods(s.handler),
+		Actions:           s.resourceKind.GetActionNames(),
+		CollectionActions: s.resourceKind.GetCollectionActionNames(),
+	}
+}
+
+func (s *Schema) DescribeFields() *resource.APIResourceSchema {
+	return &resource.APIResourceSchema{
+		Type:   "resourceSchema",
+		Name:   s.resourceName,
+		Kind:   s.resourceKindName,
+		Fields: s.descriptions,
+	}
+}
+
+func (s *Schema) GetAPIVersion() *resource.APIVersion {
+	return s.version
+}
+
+func (s *Schema) GetMiddlewares() []resource.ScopedMiddleware {
+	return s.options.Middlewares
+}
+
 func (s *Schema) GetChildren() []*Schema {
 	return s.children
 }
 
-func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segments []string, method, action string, body []byte) (resource.Resource, *goresterr.APIError) {
+func (s *Schema) CreateResourceFromPathSegments(ctx context.Context, parent resource.Resource, segments []string, method, action string, body []byte) (resource.Resource, *goresterr.APIError) {
 	segmentCount := len(segments)
 	if segmentCount == 0 {
 		return parent, nil
@@ -88,7 +140,7 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 		r.SetID(segments[1])
 	}
 	if segmentCount <= 2 {
-		if err := s.validateAndFillResource(r, method, action, body); err != nil {
+		if err := s.validateAndFillResource(ctx, r, method, action, body); err != nil {
 			return nil, err
 		} else {
 			return r, nil
@@ -96,7 +148,7 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 	}
 
 	for _, child := range s.children {
-		if r, err := child.CreateResourceFromPathSegments(r, segments[2:], method, action, body); err != nil {
+		if r, err := child.CreateResourceFromPathSegments(ctx, r, segments[2:], method, action, body); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
@@ -106,44 +158,141 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 		fmt.Sprintf("%s is not a child of %s", segments[2], s.resourceName))
 }
 
-func (s *Schema) validateAndFillResource(r resource.Resource, method, action string, body []byte) *goresterr.APIError {
+//body is decoded again to check required fields and validate them
+func (s *Schema) validateAndFillResource(ctx context.Context, r resource.Resource, method, action string, body []byte) *goresterr.APIError {
+	_, span := trace.StartSpan(ctx, trace.ValidateSpan)
+	defer span.End()
+	err := s.doValidateAndFillResource(r, method, action, body)
+	if err != nil {
+		span.SetError(err)
+	}
+	return err
+}
+
+func (s *Schema) doValidateAndFillResource(r resource.Resource, method, action string, body []byte) *goresterr.APIError {
 	if method == http.MethodPost && action != "" {
-		if action_, err := s.parseAction(action, body); err != nil {
+		if action_, err := s.parseAction(action, body, r.GetID() == ""); err != nil {
 			return err
 		} else {
 			r.SetAction(action_)
 		}
 	} else if method == http.MethodPost || method == http.MethodPut {
-		if body != nil {
-			json.Unmarshal(body, r)
+		return s.fillResource(r, body)
+	}
+	return nil
+}
+
+func (s *Schema) fillResource(r resource.Resource, body []byte) *goresterr.APIError {
+	objMap := make(map[string]interface{})
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &objMap); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("request body isn't a string map:%s", err.Error()))
 		}
-		if s.fields != nil {
-			objMap := make(map[string]interface{})
-			if body != nil {
-				if err := json.Unmarshal(body, &objMap); err != nil {
-					return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("request body isn't a string map:%s", err.Error()))
-				}
-			}
-			if err := s.fields.Validate(r, objMap); err != nil {
-				return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
-			}
+		if err := s.decodeJSON(r, body); err != nil {
+			return err
+		}
+	}
+
+	if s.fields != nil {
+		if err := s.fields.Validate(r, objMap); err != nil {
+			return fieldsError(err)
 		}
 	}
 	return nil
 }
 
-func (s *Schema) parseAction(name string, body []byte) (*resource.Action, *goresterr.APIError) {
-	if s.handler.GetActionHandler() == nil {
-		return nil, goresterr.NewAPIError(goresterr.NotFound,
-			fmt.Sprintf("no handler for action %s", name))
+//type of json value is checked before decode, since encoding/json only
+//returns the first mismatch without the index of slice, body should be
+//valid json
+func (s *Schema) decodeJSON(v interface{}, body []byte) *goresterr.APIError {
+	var raw interface{}
+	if err := unmarshalUseNumber(body, &raw); err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("decode request body failed:%s", err.Error()))
+	}
+	if err := resourcefield.CheckJSONType(reflect.TypeOf(v), raw, s.options.StrictFields); err != nil {
+		return typeCheckError(err.(resourcefield.FieldErrors))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidType, fmt.Sprintf("decode request body failed:%s", err.Error()))
 	}
+	return nil
+}
+
+//like the error returned by encoding/json, top level code is InvalidType,
+//or UnknownField if all the fields are unknown in strict mode
+func typeCheckError(errs resourcefield.FieldErrors) *goresterr.APIError {
+	code := goresterr.UnknownField
+	for _, e := range errs {
+		if e.Code == goresterr.InvalidType {
+			code = goresterr.InvalidType
+			break
+		}
+	}
+	return goresterr.NewAPIErrorWithDetails(code, errs.Error(), errs.Details())
+}
+
+func fieldsError(err error) *goresterr.APIError {
+	if errs, ok := err.(resourcefield.FieldErrors); ok {
+		return goresterr.NewAPIErrorWithDetails(goresterr.InvalidBodyContent, err.Error(), errs.Details())
+	}
+	return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+}
 
-	if action := s.resourceKind.CreateAction(name); action != nil {
+func (s *Schema) PatchResource(r resource.Resource, current resource.Resource, patchType string, patch []byte) (resource.Resource, *goresterr.APIError) {
+	if patchType != resource.MergePatchType && patchType != resource.JSONPatchType {
+		return nil, goresterr.NewAPIError(goresterr.UnsupportedMediaType,
+			fmt.Sprintf("patch with content type %s isn't supported", patchType))
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal current resource failed:%s", err.Error()))
+	}
+
+	patched, err := applyPatch(patchType, doc, patch)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("apply patch failed:%s", err.Error()))
+	}
+
+	//patched document is complete, so default resource isn't used
+	nr := reflect.New(reflect.TypeOf(s.resourceKind)).Interface().(resource.Resource)
+	if err := s.fillResource(nr, patched); err != nil {
+		return nil, err
+	}
+
+	nr.SetSchema(s)
+	nr.SetParent(r.GetParent())
+	nr.SetType(r.GetType())
+	nr.SetID(r.GetID())
+	return nr, nil
+}
+
+func (s *Schema) parseAction(name string, body []byte, isCollectionAction bool) (*resource.Action, *goresterr.APIError) {
+	var action *resource.Action
+	if isCollectionAction {
+		if s.handler.GetCollectionActionHandler() == nil {
+			return nil, goresterr.NewAPIError(goresterr.NotFound,
+				fmt.Sprintf("no handler for collection action %s", name))
+		}
+		action = s.resourceKind.CreateCollectionAction(name)
+	} else {
+		if s.handler.GetActionHandler() == nil {
+			return nil, goresterr.NewAPIError(goresterr.NotFound,
+				fmt.Sprintf("no handler for action %s", name))
+		}
+		action = s.resourceKind.CreateAction(name)
+	}
+
+	if action != nil {
 		if action.Input != nil {
-			if err := json.Unmarshal(body, action.Input); err != nil {
+			var raw json.RawMessage
+			if err := json.Unmarshal(body, &raw); err != nil {
 				return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
 					fmt.Sprintf("failed to parse action params: %s", err.Error()))
 			}
+			if err := s.decodeJSON(action.Input, body); err != nil {
+				return nil, err
+			}
 		}
 		return action, nil
 	} else {
@@ -272,7 +421,28 @@ func (s *Schema) AddLinksToResourceCollection(rs *resource.ResourceCollection, h
 		r.SetLinks(s.generateResourceLinks(r, cl))
 	}
 
-	rs.SetLinks(map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)})
+	links := map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)}
+	if s.GetHandler().GetDeleteCollectionHandler() != nil {
+		links[resource.RemoveLink] = resource.ResourceLink(cl)
+	}
+	if s.GetHandler().GetCollectionActionHandler() != nil {
+		actions := make(map[string]resource.ResourceLink)
+		for _, name := range s.resourceKind.GetCollectionActionNames() {
+			actions[name] = resource.ResourceLink(cl + "?action=" + url.QueryEscape(name))
+		}
+		if len(actions) > 0 {
+			rs.Actions = actions
+		}
+	}
+	if p := rs.Pagination; p != nil {
+		if query := p.NextQuery(); query != "" {
+			links[resource.NextLink] = resource.ResourceLink(cl + "?" + query)
+		}
+		if query := p.PreviousQuery(); query != "" {
+			links[resource.PrevLink] = resource.ResourceLink(cl + "?" + query)
+		}
+	}
+	rs.SetLinks(links)
 	return nil
 }
 
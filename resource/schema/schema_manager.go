@@ -1,12 +1,16 @@
 package schema
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path"
 
+	"github.com/ben-han-cn/gorest/codec"
 	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 type SchemaManager struct {
@@ -19,13 +23,13 @@ func NewSchemaManager() *SchemaManager {
 	return &SchemaManager{}
 }
 
-func (m *SchemaManager) MustImport(v *resource.APIVersion, kind resource.ResourceKind, handler interface{}) {
-	if err := m.Import(v, kind, handler); err != nil {
+func (m *SchemaManager) MustImport(v *resource.APIVersion, kind resource.ResourceKind, handler interface{}, opts ...resource.ImportOption) {
+	if err := m.Import(v, kind, handler, opts...); err != nil {
 		panic("!!! import get err " + err.Error())
 	}
 }
 
-func (m *SchemaManager) Import(v *resource.APIVersion, kind resource.ResourceKind, handler interface{}) error {
+func (m *SchemaManager) Import(v *resource.APIVersion, kind resource.ResourceKind, handler interface{}, opts ...resource.ImportOption) error {
 	handler_, err := resource.HandlerAdaptor(handler)
 	if err != nil {
 		return err
@@ -36,7 +40,7 @@ func (m *SchemaManager) Import(v *resource.APIVersion, kind resource.ResourceKin
 		vs = NewVersionedSchemas(v)
 		m.schemas = append(m.schemas, vs)
 	}
-	return vs.Import(kind, handler_)
+	return vs.Import(kind, handler_, opts...)
 }
 
 func (m *SchemaManager) getVersionedSchemas(v *resource.APIVersion) *VersionedSchemas {
@@ -49,6 +53,16 @@ func (m *SchemaManager) getVersionedSchemas(v *resource.APIVersion) *VersionedSc
 }
 
 func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.Resource, *goresterr.APIError) {
+	ctx, span := trace.StartSpan(req.Context(), trace.ParseSpan)
+	defer span.End()
+	r, err := m.createResourceFromRequest(ctx, req)
+	if err != nil {
+		span.SetError(err)
+	}
+	return r, err
+}
+
+func (m *SchemaManager) createResourceFromRequest(ctx context.Context, req *http.Request) (resource.Resource, *goresterr.APIError) {
 	path := multiSlashRegexp.ReplaceAllString(req.URL.EscapedPath(), "/")
 	var action string
 	if req.Method == http.MethodPost {
@@ -64,10 +78,15 @@ func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.R
 				fmt.Sprintf("failed to read request body: %s", err.Error()))
 		}
 		defer req.Body.Close()
+
+		var apiErr *goresterr.APIError
+		if body, apiErr = requestBodyToJSON(req.Header.Get("Content-Type"), body); apiErr != nil {
+			return nil, apiErr
+		}
 	}
 
 	for _, vs := range m.schemas {
-		if r, err := vs.CreateResourceFromRequest(req.Method, path, body, action); err != nil {
+		if r, err := vs.CreateResourceFromRequest(ctx, req.Method, path, body, action); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, err
@@ -76,6 +95,28 @@ func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.R
 	return nil, goresterr.NewAPIError(goresterr.NotFound, fmt.Sprintf("%s has unknown api version", req.URL.Path))
 }
 
+//validation and default value are based on json, so body encoded
+//by other codec is converted into json first
+func requestBodyToJSON(contentType string, body []byte) ([]byte, *goresterr.APIError) {
+	if len(body) == 0 {
+		return body, nil
+	}
+
+	//body with content type which has no codec is treated as json like
+	//before codec is supported, curl post data as form by default
+	c := codec.ForContentType(contentType)
+	if c == nil {
+		return body, nil
+	}
+
+	data, err := codec.ToJSON(c, body)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			fmt.Sprintf("failed to decode request body: %s", err.Error()))
+	}
+	return data, nil
+}
+
 func (m *SchemaManager) GetSchema(v *resource.APIVersion, kind resource.ResourceKind) resource.Schema {
 	if vs := m.getVersionedSchemas(v); vs != nil {
 		return vs.GetSchema(kind)
@@ -85,12 +126,72 @@ func (m *SchemaManager) GetSchema(v *resource.APIVersion, kind resource.Resource
 
 func (m *SchemaManager) GenerateResourceRoute() resource.ResourceRoute {
 	route := resource.NewResourceRoute()
+	route.AddPathForMethod(http.MethodGet, resource.GroupPrefix)
 	for _, vs := range m.schemas {
+		route.AddPathForMethod(http.MethodGet, vs.versionUrl)
+		for _, s := range uniqueSchemas(vs) {
+			route.AddPathForMethod(http.MethodGet, path.Join(vs.versionUrl, resource.SchemasPath, s.resourceKindName))
+		}
 		route = route.Merge(vs.GenerateResourceRoute())
 	}
 	return route
 }
 
+func (m *SchemaManager) GetAPIVersionList() *resource.APIVersionList {
+	versions := make([]resource.APIVersion, 0, len(m.schemas))
+	for _, vs := range m.schemas {
+		versions = append(versions, *vs.version)
+	}
+	return &resource.APIVersionList{
+		Type:     "apiVersionList",
+		Versions: versions,
+	}
+}
+
+func (m *SchemaManager) GetAPIResourceList(v *resource.APIVersion) *resource.APIResourceList {
+	vs := m.getVersionedSchemas(v)
+	if vs == nil {
+		return nil
+	}
+
+	schemas := uniqueSchemas(vs)
+	resources := make([]resource.APIResource, 0, len(schemas))
+	for _, s := range schemas {
+		resources = append(resources, s.Describe())
+	}
+	return &resource.APIResourceList{
+		Type:      "apiResourceList",
+		Group:     v.Group,
+		Version:   v.Version,
+		Resources: resources,
+	}
+}
+
+func (m *SchemaManager) GetAPIResourceSchema(v *resource.APIVersion, kind string) *resource.APIResourceSchema {
+	vs := m.getVersionedSchemas(v)
+	if vs == nil {
+		return nil
+	}
+
+	for _, s := range uniqueSchemas(vs) {
+		if s.resourceKindName == kind {
+			return s.DescribeFields()
+		}
+	}
+	return nil
+}
+
+//schema with several parents is child of each parent
+func uniqueSchemas(vs *VersionedSchemas) []*Schema {
+	var schemas []*Schema
+	for _, s := range getSchemas(vs) {
+		if isExist(schemas, s) == false {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
 func getSchemas(vs *VersionedSchemas) []*Schema {
 	var schemas []*Schema
 	for _, topSchema := range vs.toplevelSchemas {
@@ -46,9 +46,23 @@ func TestGenerateResourceRoute(t *testing.T) {
 	}
 	sort.StringSlice(expectGetAndPostPaths).Sort()
 	sort.StringSlice(expectDeleteAndPutPaths).Sort()
+	expectGetPaths := append([]string{
+		"/apis",
+		"/apis/testing/v1",
+		"/apis/testing/v1/schemas/cluster",
+		"/apis/testing/v1/schemas/node",
+		"/apis/testing/v1/schemas/namespace",
+		"/apis/testing/v1/schemas/deployment",
+		"/apis/testing/v1/schemas/statefulset",
+		"/apis/testing/v1/schemas/daemonset",
+		"/apis/testing/v1/schemas/pod",
+	}, expectGetAndPostPaths...)
+	sort.StringSlice(expectGetPaths).Sort()
 	for method, urls := range mgr.GenerateResourceRoute() {
 		sort.StringSlice(urls).Sort()
-		if method == http.MethodGet || method == http.MethodPost {
+		if method == http.MethodGet {
+			ut.Equal(t, urls, expectGetPaths)
+		} else if method == http.MethodPost {
 			ut.Equal(t, urls, expectGetAndPostPaths)
 		} else {
 			ut.Equal(t, urls, expectDeleteAndPutPaths)
@@ -230,3 +244,43 @@ func TestAddResourceCollectionLink(t *testing.T) {
 		}
 	}
 }
+
+func TestDiscovery(t *testing.T) {
+	mgr := createSchemaManager()
+	versions := mgr.GetAPIVersionList()
+	ut.Equal(t, versions.Versions, []resource.APIVersion{version})
+
+	ut.Assert(t, mgr.GetAPIResourceList(&resource.APIVersion{Group: "testing", Version: "v2"}) == nil, "")
+
+	resources := mgr.GetAPIResourceList(&version)
+	ut.Equal(t, resources.Group, "testing")
+	ut.Equal(t, resources.Version, "v1")
+	ut.Equal(t, len(resources.Resources), 7)
+
+	var pod, namespace resource.APIResource
+	for _, r := range resources.Resources {
+		switch r.Kind {
+		case "pod":
+			pod = r
+		case "namespace":
+			namespace = r
+		}
+	}
+	ut.Equal(t, pod.Name, "pods")
+	ut.Equal(t, pod.Parents, []string{"deployment", "daemonset", "statefulset"})
+	ut.Equal(t, len(pod.Children), 0)
+	ut.Equal(t, pod.Actions, []string{"move"})
+	ut.Equal(t, pod.CollectionActions, []string{"evict"})
+	ut.Equal(t, namespace.Parents, []string{"cluster"})
+	ut.Equal(t, namespace.Children, []string{"deployment", "statefulset", "daemonset"})
+}
+
+func TestGetAPIResourceSchema(t *testing.T) {
+	mgr := createSchemaManager()
+	ut.Assert(t, mgr.GetAPIResourceSchema(&version, "unknown") == nil, "")
+
+	schema := mgr.GetAPIResourceSchema(&version, "pod")
+	ut.Equal(t, schema.Name, "pods")
+	ut.Equal(t, schema.Kind, "pod")
+	ut.Assert(t, len(schema.Fields) > 0, "")
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+	"github.com/ben-han-cn/gorest/util"
+)
+
+type sortField struct {
+	field      *resourcefield.FieldPath
+	kind       util.Kind
+	descending bool
+}
+
+//keys are validated even if there is nothing to sort, so pass nil
+//resources to only validate the keys
+func (s *Schema) SortResources(keys []resource.SortKey, rs []resource.Resource) *goresterr.APIError {
+	fields, err := s.sortFields(keys)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) == 0 || len(rs) < 2 {
+		return nil
+	}
+
+	values := make([][]interface{}, len(rs))
+	for i, r := range rs {
+		values[i] = make([]interface{}, len(fields))
+		v := reflect.ValueOf(r)
+		for j, f := range fields {
+			if fv, ok := f.field.Value(v); ok {
+				values[i][j] = leafValue(f.kind, fv)
+			}
+		}
+	}
+
+	//sort the index, since resources and values should be swapped together
+	index := make([]int, len(rs))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		a, b := values[index[i]], values[index[j]]
+		for k, f := range fields {
+			c := compareSortValue(a[k], b[k])
+			if c == 0 {
+				continue
+			}
+			if f.descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := make([]resource.Resource, len(rs))
+	for i, j := range index {
+		sorted[i] = rs[j]
+	}
+	copy(rs, sorted)
+	return nil
+}
+
+func (s *Schema) sortFields(keys []resource.SortKey) ([]sortField, *goresterr.APIError) {
+	fields := make([]sortField, 0, len(keys))
+	for _, key := range keys {
+		field, err := resourcefield.LookupField(reflect.TypeOf(s.resourceKind), key.Name)
+		if err != nil {
+			return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("sort on unknown field %s", key.Name))
+		}
+
+		kind := filterKind(field.Type())
+		if kind == "" || isSliceKind(kind) {
+			return nil, goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("field %s doesn't support sort", key.Name))
+		}
+		fields = append(fields, sortField{
+			field:      field,
+			kind:       kind,
+			descending: key.Descending,
+		})
+	}
+	return fields, nil
+}
+
+//nil value which means pointer in the field path is nil is the smallest
+func compareSortValue(a, b interface{}) int {
+	if a == nil || b == nil {
+		if a == b {
+			return 0
+		} else if a == nil {
+			return -1
+		}
+		return 1
+	}
+	return compareValue(a, b)
+}
@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+)
+
+func TestSortResources(t *testing.T) {
+	handler, _ := resource.HandlerAdaptor(&resource.DumbHandler{})
+	s, _ := NewSchema(&version, Pod{}, handler)
+
+	now := time.Now()
+	newPods := func() []resource.Resource {
+		var pods []resource.Resource
+		for i, name := range []string{"b", "a", "b", "c", "a"} {
+			pod := &Pod{Name: name, Count: uint32(i % 2)}
+			if i != 3 {
+				pod.OtherInfoPointer = &OtherPodInfo{Name: name}
+			}
+			pod.SetID(string(rune('0' + i)))
+			pod.SetCreationTimestamp(now.Add(time.Duration(i) * time.Minute))
+			pods = append(pods, pod)
+		}
+		return pods
+	}
+
+	cases := []struct {
+		keys []resource.SortKey
+		ids  string
+	}{
+		{nil, "01234"},
+		{[]resource.SortKey{{Name: "Name"}}, "14023"},
+		{[]resource.SortKey{{Name: "Name", Descending: true}}, "30214"},
+		{[]resource.SortKey{{Name: "Name"}, {Name: "creationTimestamp", Descending: true}}, "41203"},
+		{[]resource.SortKey{{Name: "Count"}, {Name: "Name"}}, "40213"},
+		{[]resource.SortKey{{Name: "OtherInfoPointer.Name"}}, "31402"},
+	}
+	for _, tc := range cases {
+		pods := newPods()
+		err := s.SortResources(tc.keys, pods)
+		ut.Assert(t, err == nil, "sort %v failed %v", tc.keys, err)
+		var ids string
+		for _, pod := range pods {
+			ids += pod.GetID()
+		}
+		ut.Equal(t, ids, tc.ids)
+	}
+
+	for _, key := range []string{"unknown", "Annotations", "OtherInfo.Numbers"} {
+		err := s.SortResources([]resource.SortKey{{Name: key}}, newPods())
+		ut.Equal(t, err.ErrorCode, goresterr.InvalidOption)
+		err = s.SortResources([]resource.SortKey{{Name: key}}, nil)
+		ut.Equal(t, err.ErrorCode, goresterr.InvalidOption)
+	}
+}
@@ -94,6 +94,24 @@ func (c Pod) CreateAction(name string) *resource.Action {
 	return nil
 }
 
+func (c Pod) GetActionNames() []string {
+	return []string{"move"}
+}
+
+func (c Pod) CreateCollectionAction(name string) *resource.Action {
+	if name == "evict" {
+		return &resource.Action{
+			Name:  "evict",
+			Input: &Location{},
+		}
+	}
+	return nil
+}
+
+func (c Pod) GetCollectionActionNames() []string {
+	return []string{"evict"}
+}
+
 func (c Pod) CreateDefaultResource() resource.Resource {
 	return &Pod{
 		Count: 20,
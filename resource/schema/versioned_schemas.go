@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -29,8 +30,8 @@ func (s *VersionedSchemas) VersionEquals(v *resource.APIVersion) bool {
 	return s.version.Equal(v)
 }
 
-func (s *VersionedSchemas) Import(kind resource.ResourceKind, handler resource.Handler) error {
-	schema, err := NewSchema(s.version, kind, handler)
+func (s *VersionedSchemas) Import(kind resource.ResourceKind, handler resource.Handler, opts ...resource.ImportOption) error {
+	schema, err := NewSchema(s.version, kind, handler, opts...)
 	if err != nil {
 		return err
 	}
@@ -56,7 +57,7 @@ func (s *VersionedSchemas) Import(kind resource.ResourceKind, handler resource.H
 
 var multiSlashRegexp = regexp.MustCompile("//+")
 
-func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body []byte, action string) (resource.Resource, *goresterr.APIError) {
+func (s *VersionedSchemas) CreateResourceFromRequest(ctx context.Context, method, path string, body []byte, action string) (resource.Resource, *goresterr.APIError) {
 	if strings.HasPrefix(path, s.versionUrl) == false {
 		return nil, nil
 	}
@@ -86,7 +87,7 @@ func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body [
 	}
 
 	for _, schema := range s.toplevelSchemas {
-		if r, err := schema.CreateResourceFromPathSegments(nil, segments, method, action, body); err != nil {
+		if r, err := schema.CreateResourceFromPathSegments(ctx, nil, segments, method, action, body); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
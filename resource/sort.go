@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+const SortQueryParam = "sort"
+
+//parsed from sort query parameter like "name,-creationTimestamp",
+//field with "-" prefix is sorted in descending order
+type SortKey struct {
+	Name       string
+	Descending bool
+}
+
+func genSortKeys(query url.Values) ([]SortKey, *goresterr.APIError) {
+	var keys []SortKey
+	for _, value := range query[SortQueryParam] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			var key SortKey
+			if strings.HasPrefix(name, "-") {
+				key.Descending = true
+				name = name[1:]
+			} else if strings.HasPrefix(name, "+") {
+				name = name[1:]
+			}
+			if name == "" {
+				return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("sort %s has empty field name", value))
+			}
+			key.Name = name
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
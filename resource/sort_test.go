@@ -0,0 +1,23 @@
+package resource
+
+import (
+	"net/url"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestGenSortKeys(t *testing.T) {
+	query, _ := url.ParseQuery("sort=name,-creationTimestamp&sort=%2Bcount")
+	keys, err := genSortKeys(query)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, keys, []SortKey{
+		{Name: "name"},
+		{Name: "creationTimestamp", Descending: true},
+		{Name: "count"},
+	})
+
+	query, _ = url.ParseQuery("sort=name,,count")
+	_, err = genSortKeys(query)
+	ut.Assert(t, err != nil, "")
+}
@@ -30,6 +30,12 @@ func (h *DumbHandler) Update(ctx *Context) (Resource, *error.APIError) {
 	}, nil
 }
 
+func (h *DumbHandler) Patch(ctx *Context) (Resource, *error.APIError) {
+	return &dumbResource{
+		Number: 25,
+	}, nil
+}
+
 func (h *DumbHandler) List(ctx *Context) interface{} {
 	return []*dumbResource{&dumbResource{Number: 30}}
 }
@@ -43,3 +49,7 @@ func (h *DumbHandler) Get(ctx *Context) Resource {
 func (h *DumbHandler) Action(ctx *Context) (interface{}, *error.APIError) {
 	return 50, nil
 }
+
+func (h *DumbHandler) CollectionAction(ctx *Context) (interface{}, *error.APIError) {
+	return 55, nil
+}
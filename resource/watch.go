@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"sync"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+)
+
+type EventType string
+
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+)
+
+type WatchEvent struct {
+	Type     EventType `json:"type"`
+	Resource Resource  `json:"object"`
+}
+
+const eventBufferSize = 64
+
+//EventBroadcaster could be embedded into handler to support watch,
+//handler publishes event after resource is changed, the parent of
+//the resource should be set since events are scoped by parents.
+type EventBroadcaster struct {
+	lock     sync.Mutex
+	watchers map[chan WatchEvent]struct{}
+}
+
+func NewEventBroadcaster() *EventBroadcaster {
+	return &EventBroadcaster{
+		watchers: make(map[chan WatchEvent]struct{}),
+	}
+}
+
+func (b *EventBroadcaster) Publish(typ EventType, r Resource) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	event := WatchEvent{
+		Type:     typ,
+		Resource: r,
+	}
+	for ch := range b.watchers {
+		select {
+		case ch <- event:
+		default:
+			//slow watcher is dropped, it has to watch again
+			delete(b.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+//the returned channel is closed when the request is done
+func (b *EventBroadcaster) Watch(ctx *Context) (<-chan WatchEvent, *goresterr.APIError) {
+	ch := make(chan WatchEvent, eventBufferSize)
+	b.lock.Lock()
+	b.watchers[ch] = struct{}{}
+	b.lock.Unlock()
+
+	go func() {
+		<-ctx.Request.Context().Done()
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if _, ok := b.watchers[ch]; ok {
+			delete(b.watchers, ch)
+			close(ch)
+		}
+	}()
+	return ch, nil
+}
+
+func (b *EventBroadcaster) WatcherCount() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.watchers)
+}
@@ -1,29 +1,43 @@
 package gorest
 
 import (
-	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"path"
 	"reflect"
 
+	"github.com/ben-han-cn/gorest/codec"
 	goresterr "github.com/ben-han-cn/gorest/error"
 	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 func restHandler(ctx *resource.Context) *goresterr.APIError {
 	if ctx.Resource.GetAction() != nil {
+		if ctx.Resource.GetID() == "" {
+			return handleCollectionAction(ctx)
+		}
 		return handleAction(ctx)
 	}
 
 	switch ctx.Method {
 	case http.MethodGet:
+		if isWatch(ctx) {
+			return handleWatch(ctx)
+		}
 		return handleList(ctx)
 	case http.MethodPost:
 		return handleCreate(ctx)
 	case http.MethodPut:
 		return handleUpdate(ctx)
+	case http.MethodPatch:
+		return handlePatch(ctx)
 	case http.MethodDelete:
+		if ctx.Resource.GetID() == "" {
+			return handleDeleteCollection(ctx)
+		}
 		return handleDelete(ctx)
 	default:
 		return goresterr.NewAPIError(goresterr.NotFound, "no found request handler")
@@ -37,18 +51,19 @@ func handleCreate(ctx *resource.Context) *goresterr.APIError {
 		return goresterr.NewAPIError(goresterr.NotFound, "no handler for create")
 	}
 
+	end := startSpan(ctx, trace.HandlerSpan)
 	r, err := handler(ctx)
+	end(err)
 	if err != nil {
 		return err
 	}
 
 	ctx.Resource.SetID(r.GetID())
 	r.SetType(ctx.Resource.GetType())
-	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
-	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	if err := addLinksToResource(ctx, r); err != nil {
+		return err
 	}
-	WriteResponse(ctx.Response, http.StatusCreated, r)
+	ctx.SetResponse(http.StatusCreated, r)
 	return nil
 }
 
@@ -58,11 +73,54 @@ func handleDelete(ctx *resource.Context) *goresterr.APIError {
 		return goresterr.NewAPIError(goresterr.NotFound, "no handler for delete")
 	}
 
-	if err := handler(ctx); err != nil {
+	if needCheckPrecondition(ctx) {
+		current, err := getCurrentResource(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkPrecondition(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	end := startSpan(ctx, trace.HandlerSpan)
+	err := handler(ctx)
+	end(err)
+	if err != nil {
 		return err
 	}
 
-	WriteResponse(ctx.Response, http.StatusNoContent, nil)
+	ctx.SetResponse(http.StatusNoContent, nil)
+	return nil
+}
+
+func handleDeleteCollection(ctx *resource.Context) *goresterr.APIError {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetDeleteCollectionHandler()
+	if handler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound, "no handler for delete collection")
+	}
+
+	//unknown filter is rejected, otherwise the whole collection is deleted
+	if err := schema.ValidateFilters(ctx.GetFilters()); err != nil {
+		return err
+	}
+
+	end := startSpan(ctx, trace.HandlerSpan)
+	ids, err := handler(ctx)
+	end(err)
+	if err != nil {
+		return err
+	}
+
+	if ids == nil {
+		ids = []string{}
+	}
+	ctx.SetResponse(http.StatusOK, &resource.DeleteCollectionResult{
+		Type:         "collection",
+		ResourceType: ctx.Resource.GetType(),
+		IDs:          ids,
+	})
 	return nil
 }
 
@@ -73,17 +131,83 @@ func handleUpdate(ctx *resource.Context) *goresterr.APIError {
 		return goresterr.NewAPIError(goresterr.NotFound, "no handler for update")
 	}
 
+	if needCheckPrecondition(ctx) {
+		current, err := getCurrentResource(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkPrecondition(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	end := startSpan(ctx, trace.HandlerSpan)
 	r, err := handler(ctx)
+	end(err)
 	if err != nil {
 		return err
 	}
 
-	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
-	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	if err := addLinksToResource(ctx, r); err != nil {
+		return err
 	}
 	r.SetType(ctx.Resource.GetType())
-	WriteResponse(ctx.Response, http.StatusOK, r)
+	setETag(ctx, r)
+	ctx.SetResponse(http.StatusOK, r)
+	return nil
+}
+
+func handlePatch(ctx *resource.Context) *goresterr.APIError {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetPatchHandler()
+	if handler == nil || ctx.Resource.GetID() == "" {
+		return goresterr.NewAPIError(goresterr.NotFound, "no handler for patch")
+	}
+
+	current, apiErr := getCurrentResource(ctx)
+	if apiErr != nil {
+		return apiErr
+	}
+	if apiErr := checkPrecondition(ctx, current); apiErr != nil {
+		return apiErr
+	}
+
+	patchType, _, err := mime.ParseMediaType(ctx.Request.Header.Get(ContentTypeKey))
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.UnsupportedMediaType, fmt.Sprintf("invalid content type:%s", err.Error()))
+	}
+
+	if ctx.Request.Body == nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, "patch has no body")
+	}
+	patch, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("failed to read request body: %s", err.Error()))
+	}
+
+	patched, apiErr := schema.PatchResource(ctx.Resource, current, patchType, patch)
+	if apiErr != nil {
+		return apiErr
+	}
+	//patch which changes resource version is checked like update
+	if apiErr := checkResourceVersion(patched, current); apiErr != nil {
+		return apiErr
+	}
+	ctx.Resource = patched
+
+	end := startSpan(ctx, trace.HandlerSpan)
+	r, apiErr := handler(ctx)
+	end(apiErr)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := addLinksToResource(ctx, r); err != nil {
+		return err
+	}
+	r.SetType(ctx.Resource.GetType())
+	setETag(ctx, r)
+	ctx.SetResponse(http.StatusOK, r)
 	return nil
 }
 
@@ -96,15 +220,47 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 			return goresterr.NewAPIError(goresterr.NotFound, "no found for list")
 		}
 
+		end := startSpan(ctx, trace.HandlerSpan)
 		data := handler(ctx)
+		end(nil)
 		rc, err := resource.NewResourceCollection(ctx.Resource, data)
 		if err != nil {
 			return goresterr.NewAPIError(goresterr.ServerError, err.Error())
 		}
+		visible := visibleResources(ctx, rc.Resources)
+		//the page is short and total includes the hidden resources
+		if rc.Pagination != nil && len(visible) != len(rc.Resources) {
+			rc.Pagination.Total = nil
+		}
+		rc.Resources = visible
 
-		httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
-		if err := schema.AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
-			return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+		//handler which paginates natively should filter by itself, but the
+		//filters are still checked like sort keys
+		toFilter := rc.Resources
+		if rc.Pagination != nil {
+			toFilter = nil
+		}
+		if rs, err := schema.FilterResources(ctx.GetFilters(), toFilter); err != nil {
+			return err
+		} else if rc.Pagination == nil {
+			rc.Resources = rs
+		}
+
+		//sort keys are validated even if handler has sorted the resources
+		toSort := rc.Resources
+		if rc.IsSorted() {
+			toSort = nil
+		}
+		if err := schema.SortResources(ctx.GetSortKeys(), toSort); err != nil {
+			return err
+		}
+
+		if err := rc.Paginate(ctx); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidFormat, err.Error())
+		}
+
+		if err := addLinksToResourceCollection(ctx, rc); err != nil {
+			return err
 		}
 		result = rc
 	} else {
@@ -112,7 +268,9 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 		if handler == nil {
 			return goresterr.NewAPIError(goresterr.NotFound, "no found for list")
 		}
+		end := startSpan(ctx, trace.HandlerSpan)
 		r := handler(ctx)
+		end(nil)
 		if r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil()) {
 			return goresterr.NewAPIError(goresterr.NotFound,
 				fmt.Sprintf("%s resource with id %s doesn't exist", ctx.Resource.GetType(), ctx.Resource.GetID()))
@@ -120,40 +278,156 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 			//the resource handler returns mayn't include schema
 			r.SetSchema(ctx.Resource.GetSchema())
 			r.SetParent(ctx.Resource.GetParent())
-			httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
-			if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-				return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+			if err := addLinksToResource(ctx, r); err != nil {
+				return err
 			}
 			r.SetType(ctx.Resource.GetType())
 		}
+
+		setETag(ctx, r)
+		if checkNotModified(ctx, r) {
+			return nil
+		}
 		result = r
 	}
 
-	WriteResponse(ctx.Response, http.StatusOK, result)
+	result, err := pruneFields(ctx, result)
+	if err != nil {
+		return err
+	}
+
+	ctx.SetResponse(http.StatusOK, result)
 	return nil
 }
 
+func addLinksToResource(ctx *resource.Context, r resource.Resource) *goresterr.APIError {
+	end := startSpan(ctx, trace.LinksSpan)
+	defer end(nil)
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := ctx.Resource.GetSchema().AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	}
+	return nil
+}
+
+func addLinksToResourceCollection(ctx *resource.Context, rc *resource.ResourceCollection) *goresterr.APIError {
+	end := startSpan(ctx, trace.LinksSpan)
+	defer end(nil)
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := ctx.Resource.GetSchema().AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	}
+	return nil
+}
+
+//resources may belong to handler, so filter into a new slice
+func visibleResources(ctx *resource.Context, rs []resource.Resource) []resource.Resource {
+	visible := make([]resource.Resource, 0, len(rs))
+	for _, r := range rs {
+		if ctx.IsResourceVisible(r) {
+			visible = append(visible, r)
+		}
+	}
+	return visible
+}
+
+//only return the fields specified by client
+func pruneFields(ctx *resource.Context, result interface{}) (interface{}, *goresterr.APIError) {
+	fields := ctx.GetFields()
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	schema := ctx.Resource.GetSchema()
+	switch r := result.(type) {
+	case *resource.ResourceCollection:
+		objs, err := schema.PruneResources(fields, r.Resources)
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			*resource.ResourceCollection
+			Resources []map[string]interface{} `json:"data"`
+		}{r, objs}, nil
+	case resource.Resource:
+		objs, err := schema.PruneResources(fields, []resource.Resource{r})
+		if err != nil {
+			return nil, err
+		}
+		return objs[0], nil
+	default:
+		return result, nil
+	}
+}
+
 func handleAction(ctx *resource.Context) *goresterr.APIError {
 	handler := ctx.Resource.GetSchema().GetHandler().GetActionHandler()
 	if handler == nil {
 		return goresterr.NewAPIError(goresterr.NotFound, "no handler for action")
 	}
 
+	end := startSpan(ctx, trace.HandlerSpan)
+	result, err := handler(ctx)
+	end(err)
+	if err != nil {
+		return err
+	}
+
+	ctx.SetResponse(http.StatusOK, result)
+	return nil
+}
+
+func handleCollectionAction(ctx *resource.Context) *goresterr.APIError {
+	handler := ctx.Resource.GetSchema().GetHandler().GetCollectionActionHandler()
+	if handler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound, "no handler for collection action")
+	}
+
+	end := startSpan(ctx, trace.HandlerSpan)
 	result, err := handler(ctx)
+	end(err)
 	if err != nil {
 		return err
 	}
 
-	WriteResponse(ctx.Response, http.StatusOK, result)
+	ctx.SetResponse(http.StatusOK, result)
 	return nil
 }
 
-const ContentTypeKey = "Content-Type"
+const (
+	ContentTypeKey = "Content-Type"
+	AcceptKey      = "Accept"
+)
+
+func negotiateCodec(req *http.Request) (codec.Codec, *goresterr.APIError) {
+	c, ok := codec.Negotiate(req.Header.Get(AcceptKey))
+	if ok == false {
+		return nil, goresterr.NewAPIError(goresterr.NotAcceptable,
+			fmt.Sprintf("no acceptable content type in %s", req.Header.Get(AcceptKey)))
+	}
+
+	if c == codec.JSON && req.URL.Query().Get("pretty") == "true" {
+		c = codec.PrettyJSON
+	}
+	return c, nil
+}
+
+func writeResponse(ctx *resource.Context, status int, result interface{}) {
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		ctx.Response.WriteHeader(status)
+		return
+	}
+	WriteResponseWithCodec(ctx.Response, status, result, ctx.GetCodec())
+}
 
 func WriteResponse(resp http.ResponseWriter, status int, result interface{}) {
+	WriteResponseWithCodec(resp, status, result, codec.JSON)
+}
+
+func WriteResponseWithCodec(resp http.ResponseWriter, status int, result interface{}, c codec.Codec) {
 	var body []byte
-	resp.Header().Set(ContentTypeKey, "application/json")
-	body, _ = json.Marshal(result)
+	resp.Header().Set(ContentTypeKey, c.ContentType())
+	body, _ = c.Marshal(result)
 	resp.WriteHeader(status)
 	resp.Write(body)
 }
@@ -1,45 +1,204 @@
 package gorest
 
 import (
+	"log"
 	"net/http"
 
+	"github.com/ben-han-cn/gorest/codec"
 	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/metrics"
 	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 type HandlerFunc func(*resource.Context) *goresterr.APIError
 type HandlersChain []HandlerFunc
 
+//result of handler is kept in context and written after all the
+//middlewares return, so middleware could inspect or replace it with
+//ctx.GetResponse and ctx.SetResponse
+type Middleware = resource.Middleware
+
 type Server struct {
-	Schemas  resource.SchemaManager
-	handlers HandlersChain
+	Schemas     resource.SchemaManager
+	middlewares []Middleware
+	openAPI     bool
+	metrics     *serverMetrics
+	tracer      trace.Tracer
+	panicLogger *log.Logger
+	repanic     bool
 }
 
 func NewAPIServer(schemas resource.SchemaManager) *Server {
 	return &Server{
 		Schemas: schemas,
+		tracer:  trace.NoopTracer{},
 	}
 }
 
+//handler runs before the rest handler, request is aborted if it returns error
 func (s *Server) Use(h HandlerFunc) {
-	s.handlers = append(s.handlers, h)
+	s.UseMiddleware(func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		if err := h(ctx); err != nil {
+			return err
+		}
+		return next()
+	})
 }
 
-func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	ctx, err := resource.NewContext(rw, req, s.Schemas)
-	if err != nil {
-		WriteResponse(rw, err.Status, err)
-		return
+func (s *Server) UseMiddleware(m Middleware) {
+	s.middlewares = append(s.middlewares, m)
+}
+
+//serve OpenAPI document at OpenAPIPath, the path isn't included in the
+//resource route, it should be registered separately when use adaptor
+func (s *Server) EnableOpenAPI() {
+	s.openAPI = true
+}
+
+//collect metrics of resource requests and serve them at MetricsPath in
+//prometheus text format, the path isn't included in the resource route,
+//it should be registered separately when use adaptor. metrics requests
+//are passed to the server middlewares like discovery
+func (s *Server) EnableMetrics() {
+	if s.metrics == nil {
+		s.metrics = newServerMetrics()
 	}
+}
 
-	for _, h := range s.handlers {
-		if err := h(ctx); err != nil {
+//registry which metrics of server are in, custom metrics could be added
+//into it, return nil if metrics isn't enabled
+func (s *Server) MetricsRegistry() *metrics.Registry {
+	if s.metrics == nil {
+		return nil
+	}
+	return s.metrics.registry
+}
+
+//spans are created for each phase of resource request, trace.NoopTracer
+//is used by default
+func (s *Server) SetTracer(t trace.Tracer) {
+	s.tracer = t
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	id := requestID(req)
+	w.Header().Set(RequestIDKey, id)
+	rw := &responseWriter{ResponseWriter: w}
+
+	//invalid traceparent is ignored and new trace is started
+	parent, _ := trace.ParseTraceparent(req.Header.Get(trace.TraceparentHeader))
+	reqCtx, span := trace.Start(req.Context(), s.tracer, parent, trace.RequestSpan)
+	defer span.End()
+	defer s.recoverPanic(rw, req, span, id)
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.target", req.URL.RequestURI())
+	s.serve(rw, req.WithContext(reqCtx), span, id)
+}
+
+func (s *Server) serve(rw http.ResponseWriter, req *http.Request, span trace.Span, id string) {
+	metricsHandler := s.metricsHandler(req)
+	c, err := negotiateCodec(req)
+	if err != nil {
+		//metrics is in prometheus text format whatever is accepted
+		if metricsHandler == nil {
+			span.SetError(err)
+			span.SetAttribute("http.status_code", err.Status)
 			WriteResponse(rw, err.Status, err)
 			return
 		}
+		c, err = codec.JSON, nil
 	}
 
-	if err := restHandler(ctx); err != nil {
-		WriteResponse(rw, err.Status, err)
+	var ctx *resource.Context
+	var middlewares []Middleware
+	handler := metricsHandler
+	if handler == nil {
+		handler = s.discoveryHandler(req)
+	}
+	if handler != nil {
+		ctx = resource.NewNonResourceContext(rw, req, s.Schemas)
+		middlewares = s.middlewares
+	} else {
+		ctx, err = resource.NewContext(rw, req, s.Schemas)
+		if err != nil {
+			if s.metrics != nil {
+				s.metrics.observe(make([]string, len(requestLabels)), err.Status, err)
+			}
+			span.SetError(err)
+			span.SetAttribute("http.status_code", err.Status)
+			WriteResponseWithCodec(rw, err.Status, err, c)
+			return
+		}
+		span.SetAttribute("gorest.kind", ctx.Resource.GetType())
+		span.SetAttribute("gorest.verb", ctx.GetVerb())
+		middlewares = append(append([]Middleware{}, s.middlewares...), scopedMiddlewares(ctx)...)
+		handler = restHandler
+	}
+	ctx.SetCodec(c)
+	ctx.Set(requestIDCtxKey, id)
+
+	err = s.handleWithMetrics(ctx, middlewares, handler)
+	if err != nil {
+		span.SetError(err)
+		span.SetAttribute("http.status_code", err.Status)
+		end := startSpan(ctx, trace.SerializeSpan)
+		WriteResponseWithCodec(rw, err.Status, err, c)
+		end(nil)
+	} else if status, result := ctx.GetResponse(); status != 0 {
+		span.SetAttribute("http.status_code", status)
+		end := startSpan(ctx, trace.SerializeSpan)
+		writeResponse(ctx, status, result)
+		end(nil)
+	}
+}
+
+//metrics only include the requests on resource, request which panics is
+//counted as ServerError before the panic is recovered
+func (s *Server) handleWithMetrics(ctx *resource.Context, middlewares []Middleware, handler HandlerFunc) (err *goresterr.APIError) {
+	if s.metrics == nil || ctx.Resource == nil {
+		return handle(ctx, middlewares, handler)
+	}
+
+	done := s.metrics.begin(ctx)
+	panicked := true
+	defer func() {
+		if panicked {
+			done(goresterr.NewAPIError(goresterr.ServerError, "panic when handle request"))
+		} else {
+			done(err)
+		}
+	}()
+	err = handle(ctx, middlewares, handler)
+	panicked = false
+	return err
+}
+
+func handle(ctx *resource.Context, middlewares []Middleware, handler HandlerFunc) *goresterr.APIError {
+	if len(middlewares) == 0 {
+		return handler(ctx)
+	}
+	return middlewares[0](ctx, func() *goresterr.APIError {
+		return handle(ctx, middlewares[1:], handler)
+	})
+}
+
+//middlewares of ancestors which apply to children run first
+func scopedMiddlewares(ctx *resource.Context) []Middleware {
+	var ancestors []resource.Resource
+	for parent := ctx.Resource.GetParent(); parent != nil; parent = parent.GetParent() {
+		ancestors = append([]resource.Resource{parent}, ancestors...)
+	}
+
+	var middlewares []Middleware
+	action := ctx.Resource.GetAction()
+	for _, r := range append(ancestors, ctx.Resource) {
+		isSelf := r == ctx.Resource
+		for _, m := range r.GetSchema().GetMiddlewares() {
+			if (isSelf || m.WithChildren) && m.Match(ctx.Method, action) {
+				middlewares = append(middlewares, m.Middleware)
+			}
+		}
 	}
+	return middlewares
 }
@@ -1,14 +1,23 @@
 package gorest
 
 import (
+	"bytes"
+	"encoding/json"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/ben-han-cn/cement/slice"
 	ut "github.com/ben-han-cn/cement/unittest"
 	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/openapi"
 	"github.com/ben-han-cn/gorest/resource"
 	"github.com/ben-han-cn/gorest/resource/schema"
+	"github.com/ben-han-cn/gorest/resource/schema/resourcefield"
+	"github.com/ben-han-cn/gorest/trace"
 )
 
 var (
@@ -61,3 +70,869 @@ func TestContextPassChain(t *testing.T) {
 
 	s.ServeHTTP(w, req)
 }
+
+type Bar struct {
+	resource.ResourceBase
+	Name  string `json:"name" rest:"required=true,minLen=1,maxLen=10"`
+	Count int    `json:"count" rest:"min=1,max=10"`
+}
+
+type barHandler struct {
+	bar *Bar
+}
+
+func (h *barHandler) Get(ctx *resource.Context) resource.Resource {
+	if ctx.Resource.GetID() != h.bar.GetID() {
+		return nil
+	}
+	return h.bar
+}
+
+func (h *barHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.bar = ctx.Resource.(*Bar)
+	return h.bar, nil
+}
+
+func TestPatch(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	handler := &barHandler{bar: bar}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+
+	cases := []struct {
+		id          string
+		contentType string
+		patch       string
+		status      int
+		name        string
+		count       int
+	}{
+		{"b1", resource.MergePatchType, `{"count":5}`, http.StatusOK, "b1", 5},
+		{"b1", resource.JSONPatchType, `[{"op":"replace","path":"/name","value":"b2"}]`, http.StatusOK, "b2", 5},
+		{"b1", resource.MergePatchType, `{"count":20}`, goresterr.InvalidBodyContent.Status, "b2", 5},
+		{"b1", resource.JSONPatchType, `[{"op":"remove","path":"/name"}]`, goresterr.InvalidBodyContent.Status, "b2", 5},
+		{"b1", "application/json", `{"count":5}`, goresterr.UnsupportedMediaType.Status, "b2", 5},
+		{"b2", resource.MergePatchType, `{"count":5}`, http.StatusNotFound, "b2", 5},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bars/"+tc.id, bytes.NewBufferString(tc.patch))
+		req.Header.Set("Content-Type", tc.contentType)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, tc.status)
+		ut.Equal(t, handler.bar.Name, tc.name)
+		ut.Equal(t, handler.bar.Count, tc.count)
+		ut.Equal(t, handler.bar.GetID(), "b1")
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{bar: bar})
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bars/b1", bytes.NewBufferString(`{"name":"","count":20}`))
+	req.Header.Set("Content-Type", resource.MergePatchType)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusUnprocessableEntity)
+
+	var apiErr goresterr.APIError
+	json.Unmarshal(w.Body.Bytes(), &apiErr)
+	ut.Equal(t, apiErr.Code, goresterr.InvalidBodyContent.Code)
+	ut.Equal(t, len(apiErr.Details), 2)
+	ut.Equal(t, apiErr.Details[0].Field, "/name")
+	ut.Equal(t, apiErr.Details[0].Code, goresterr.MinLengthExceeded.Code)
+	ut.Equal(t, apiErr.Details[0].Params["minLen"], float64(1))
+	ut.Equal(t, apiErr.Details[1].Field, "/count")
+	ut.Equal(t, apiErr.Details[1].Code, goresterr.MaxLimitExceeded.Code)
+	ut.Equal(t, apiErr.Details[1].Params["max"], float64(10))
+}
+
+func TestJSONTypeMismatch(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	handler := &barHandler{bar: bar}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler, resource.WithStrictFields())
+	s := NewAPIServer(mgr)
+
+	cases := []struct {
+		patch string
+		field string
+		code  goresterr.ErrorCode
+	}{
+		{`{"count":"five"}`, "/count", goresterr.InvalidType},
+		{`{"color":"red"}`, "/color", goresterr.UnknownField},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bars/b1", bytes.NewBufferString(tc.patch))
+		req.Header.Set("Content-Type", resource.MergePatchType)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusUnprocessableEntity)
+
+		var apiErr goresterr.APIError
+		json.Unmarshal(w.Body.Bytes(), &apiErr)
+		ut.Equal(t, apiErr.Code, tc.code.Code)
+		ut.Equal(t, len(apiErr.Details), 1)
+		ut.Equal(t, apiErr.Details[0].Field, tc.field)
+		ut.Equal(t, apiErr.Details[0].Code, tc.code.Code)
+		if tc.code == goresterr.InvalidType {
+			ut.Equal(t, apiErr.Details[0].Params["expected"], "integer")
+			ut.Equal(t, apiErr.Details[0].Params["received"], "string")
+		}
+	}
+	ut.Equal(t, handler.bar.Count, 2)
+
+	//max int64 is an integer, it's rejected by the max rule of count
+	req, _ := http.NewRequest(http.MethodPatch, "/apis/testing/v1/bars/b1", bytes.NewBufferString(`{"count":9223372036854775807}`))
+	req.Header.Set("Content-Type", resource.MergePatchType)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	var apiErr goresterr.APIError
+	json.Unmarshal(w.Body.Bytes(), &apiErr)
+	ut.Equal(t, apiErr.Code, goresterr.InvalidBodyContent.Code)
+	ut.Equal(t, apiErr.Details[0].Code, goresterr.MaxLimitExceeded.Code)
+}
+
+func TestContentNegotiation(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{bar: bar})
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1", nil)
+	req.Header.Set("Accept", "application/yaml")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get("Content-Type"), "application/yaml")
+	ut.Assert(t, strings.Contains(w.Body.String(), "name: b1\n"), "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1?pretty=true", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Assert(t, strings.Contains(w.Body.String(), "\n  \"name\": \"b1\""), "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.NotAcceptable.Status)
+	ut.Equal(t, w.Header().Get("Content-Type"), "application/json")
+
+	req, _ = http.NewRequest(http.MethodPatch, "/apis/testing/v1/bars/b1", bytes.NewBufferString(`{"count":20}`))
+	req.Header.Set("Content-Type", resource.MergePatchType)
+	req.Header.Set("Accept", "application/yaml")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	ut.Assert(t, strings.Contains(w.Body.String(), "code: InvalidBodyContent\n"), "")
+}
+
+type barListHandler struct {
+	bars []*Bar
+}
+
+func (h *barListHandler) List(ctx *resource.Context) interface{} {
+	return h.bars
+}
+
+func TestPagination(t *testing.T) {
+	handler := &barListHandler{}
+	for i := 0; i < 5; i++ {
+		bar := &Bar{Name: "b", Count: i + 1}
+		bar.SetID(strconv.Itoa(i))
+		handler.bars = append(handler.bars, bar)
+	}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+
+	link := "/apis/testing/v1/bars?limit=2&name=b"
+	var ids []string
+	for link != "" {
+		req, _ := http.NewRequest(http.MethodGet, link, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusOK)
+		var rc struct {
+			Links      map[string]string `json:"links"`
+			Pagination struct {
+				Total    int    `json:"total"`
+				PageSize int    `json:"pageSize"`
+				Continue string `json:"continue"`
+			} `json:"pagination"`
+			Data []Bar `json:"data"`
+		}
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &rc) == nil, "")
+		ut.Equal(t, rc.Pagination.Total, 5)
+		ut.Equal(t, rc.Pagination.PageSize, 2)
+		for _, bar := range rc.Data {
+			ids = append(ids, bar.GetID())
+		}
+		if len(ids) > 2 {
+			ut.Assert(t, rc.Links["prev"] != "", "")
+		}
+		link = rc.Links["next"]
+		ut.Equal(t, link == "", rc.Pagination.Continue == "")
+		if link != "" {
+			ut.Assert(t, strings.Contains(link, "name=b"), "")
+		}
+	}
+	ut.Equal(t, ids, []string{"0", "1", "2", "3", "4"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?limit=2&continue=invalid", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?limit=abc", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+}
+
+type sortedBarListHandler struct {
+	bars []*Bar
+}
+
+func (h *sortedBarListHandler) List(ctx *resource.Context) interface{} {
+	return &resource.ListResult{Data: h.bars, Sorted: true}
+}
+
+func TestSort(t *testing.T) {
+	handler := &barListHandler{}
+	for i := 0; i < 5; i++ {
+		bar := &Bar{Name: "b", Count: i + 1}
+		bar.SetID(strconv.Itoa(i))
+		handler.bars = append(handler.bars, bar)
+	}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+
+	link := "/apis/testing/v1/bars?limit=2&sort=-count"
+	var ids []string
+	for link != "" {
+		req, _ := http.NewRequest(http.MethodGet, link, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusOK)
+		var rc struct {
+			Links map[string]string `json:"links"`
+			Data  []Bar             `json:"data"`
+		}
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &rc) == nil, "")
+		for _, bar := range rc.Data {
+			ids = append(ids, bar.GetID())
+		}
+		link = rc.Links["next"]
+		if link != "" {
+			ut.Assert(t, strings.Contains(link, "sort=-count"), "")
+		}
+	}
+	ut.Equal(t, ids, []string{"4", "3", "2", "1", "0"})
+
+	//invalid key is rejected no matter how many resources are returned
+	//and whether handler sorts by itself
+	for _, h := range []interface{}{&barListHandler{}, &barListHandler{bars: handler.bars[:1]}, &sortedBarListHandler{bars: handler.bars}} {
+		mgr := schema.NewSchemaManager()
+		mgr.Import(&version, Bar{}, h)
+		req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?sort=bogus", nil)
+		w := httptest.NewRecorder()
+		NewAPIServer(mgr).ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.InvalidOption.Status)
+	}
+}
+
+type pagedBarListHandler struct {
+	bars []*Bar
+}
+
+func (h *pagedBarListHandler) List(ctx *resource.Context) interface{} {
+	return &resource.ListResult{Data: h.bars, Paginated: true, Total: 5, Continue: "next"}
+}
+
+func TestPredicateWithNativePagination(t *testing.T) {
+	handler := &pagedBarListHandler{}
+	for i := 0; i < 2; i++ {
+		bar := &Bar{Name: "b", Count: i + 1}
+		bar.SetID(strconv.Itoa(i))
+		handler.bars = append(handler.bars, bar)
+	}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+	hidden := ""
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		ctx.AddResourcePredicate(func(r resource.Resource) bool {
+			return r.GetID() != hidden
+		})
+		return nil
+	})
+
+	list := func() (*int, int) {
+		req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?limit=2", nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusOK)
+		var rc struct {
+			Pagination map[string]interface{} `json:"pagination"`
+			Data       []Bar                  `json:"data"`
+		}
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &rc) == nil, "")
+		if total, ok := rc.Pagination["total"]; ok {
+			n := int(total.(float64))
+			return &n, len(rc.Data)
+		}
+		return nil, len(rc.Data)
+	}
+
+	total, count := list()
+	ut.Equal(t, *total, 5)
+	ut.Equal(t, count, 2)
+
+	hidden = "0"
+	total, count = list()
+	ut.Assert(t, total == nil, "total should be omitted")
+	ut.Equal(t, count, 1)
+}
+
+func TestFilterWithNativePagination(t *testing.T) {
+	handler := &pagedBarListHandler{bars: []*Bar{&Bar{Name: "b", Count: 1}}}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler, resource.WithGenericFilter())
+	s := NewAPIServer(mgr)
+
+	for query, status := range map[string]int{
+		"name=a":       http.StatusOK,
+		"nmae=a":       goresterr.InvalidOption.Status,
+		"name_bogus=a": goresterr.InvalidOption.Status,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?"+query, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, status)
+	}
+}
+
+func TestSparseFields(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{bar: bar})
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1?fields=count", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var obj map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &obj)
+	ut.Equal(t, obj["count"], float64(2))
+	ut.Equal(t, obj["id"], "b1")
+	ut.Equal(t, obj["type"], "bar")
+	ut.Assert(t, obj["links"] != nil, "")
+	_, ok := obj["name"]
+	ut.Assert(t, ok == false, "")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1?fields=unknown", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidOption.Status)
+
+	handler := &barListHandler{bars: []*Bar{bar}}
+	mgr = schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s = NewAPIServer(mgr)
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars?fields=name", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var rc struct {
+		Type string                   `json:"type"`
+		Data []map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &rc)
+	ut.Equal(t, rc.Type, "collection")
+	ut.Equal(t, len(rc.Data), 1)
+	ut.Equal(t, rc.Data[0]["name"], "b1")
+	_, ok = rc.Data[0]["count"]
+	ut.Assert(t, ok == false, "")
+}
+
+func (h *barListHandler) DeleteCollection(ctx *resource.Context) ([]string, *goresterr.APIError) {
+	var names []string
+	for _, filter := range ctx.GetFilters() {
+		if filter.Name == "name" {
+			names = filter.Value
+		}
+	}
+
+	var ids []string
+	var left []*Bar
+	for _, bar := range h.bars {
+		if len(names) == 0 || slice.SliceIndex(names, bar.Name) != -1 {
+			ids = append(ids, bar.GetID())
+		} else {
+			left = append(left, bar)
+		}
+	}
+	h.bars = left
+	return ids, nil
+}
+
+func TestDeleteCollection(t *testing.T) {
+	handler := &barListHandler{}
+	for i, name := range []string{"a", "b", "a"} {
+		bar := &Bar{Name: name, Count: 1}
+		bar.SetID(strconv.Itoa(i))
+		handler.bars = append(handler.bars, bar)
+	}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler, resource.WithGenericFilter())
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/apis/testing/v1/bars?unknown=a", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidOption.Status)
+	ut.Equal(t, len(handler.bars), 3)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/apis/testing/v1/bars?name=a", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var result resource.DeleteCollectionResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	ut.Equal(t, result.ResourceType, "bar")
+	ut.Equal(t, result.IDs, []string{"0", "2"})
+	ut.Equal(t, len(handler.bars), 1)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Assert(t, strings.Contains(w.Body.String(), `"remove":"/apis/testing/v1/bars"`), "")
+
+	req, _ = http.NewRequest(http.MethodDelete, "/apis/testing/v1/bars", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &result)
+	ut.Equal(t, result.IDs, []string{"1"})
+	ut.Equal(t, len(handler.bars), 0)
+
+	//filters are validated even if generic filter isn't enabled
+	handler.bars = []*Bar{&Bar{Name: "a", Count: 1}}
+	mgr = schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s = NewAPIServer(mgr)
+	for _, query := range []string{"nmae=a", "name_bogus=a"} {
+		req, _ = http.NewRequest(http.MethodDelete, "/apis/testing/v1/bars?"+query, nil)
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.InvalidOption.Status)
+		ut.Equal(t, len(handler.bars), 1)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{})
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var versions resource.APIVersionList
+	json.Unmarshal(w.Body.Bytes(), &versions)
+	ut.Equal(t, versions.Versions, []resource.APIVersion{version})
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var resources resource.APIResourceList
+	json.Unmarshal(w.Body.Bytes(), &resources)
+	ut.Equal(t, len(resources.Resources), 1)
+	ut.Equal(t, resources.Resources[0].Name, "bars")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v2", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/schemas/bar", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var schema resource.APIResourceSchema
+	json.Unmarshal(w.Body.Bytes(), &schema)
+	ut.Equal(t, schema.Kind, "bar")
+	fields := make(map[string]resourcefield.FieldDescription)
+	for _, field := range schema.Fields {
+		fields[field.Name] = field
+	}
+	ut.Equal(t, fields["creationTimestamp"].Type, "time")
+	ut.Equal(t, fields["name"].Constraints, map[string]interface{}{
+		"required":        true,
+		"minLen":          float64(1),
+		"maxLen":          float64(10),
+		"exclusiveMaxLen": true,
+	})
+	ut.Equal(t, fields["count"].Type, "int")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/schemas/foo", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+
+	//discovery requests are passed to the middlewares without resource
+	s.EnableOpenAPI()
+	var paths []string
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		ut.Assert(t, ctx.Resource == nil, "")
+		paths = append(paths, ctx.Request.URL.Path)
+		return goresterr.NewAPIError(goresterr.Unauthorized, "")
+	})
+	discoveryPaths := []string{"/apis", "/apis/testing/v1", "/apis/testing/v1/schemas/bar", OpenAPIPath}
+	for _, path := range discoveryPaths {
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.Unauthorized.Status)
+	}
+	ut.Equal(t, paths, discoveryPaths)
+}
+
+func TestOpenAPI(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{})
+	s := NewAPIServer(mgr)
+
+	req, _ := http.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+
+	s.EnableOpenAPI()
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var doc openapi.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	ut.Equal(t, doc.OpenAPI, openapi.Version)
+	_, ok := doc.Paths["/apis/testing/v1/bars/{bar_id}"]["get"]
+	ut.Assert(t, ok, "")
+}
+
+func TestMiddleware(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{bar: bar})
+	s := NewAPIServer(mgr)
+
+	var calls []string
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		calls = append(calls, "pre")
+		return nil
+	})
+	s.UseMiddleware(func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		calls = append(calls, "before")
+		err := next()
+		calls = append(calls, "after")
+		if err != nil {
+			return err
+		}
+
+		status, result := ctx.GetResponse()
+		ut.Equal(t, status, http.StatusOK)
+		r := *(result.(*Bar))
+		r.Name = "changed"
+		ctx.SetResponse(http.StatusAccepted, &r)
+		return nil
+	})
+	s.UseMiddleware(func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		if ctx.Resource.GetID() == "forbidden" {
+			return goresterr.NewAPIError(goresterr.PermissionDenied, "")
+		}
+		return next()
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, calls, []string{"pre", "before", "after"})
+	ut.Equal(t, w.Code, http.StatusAccepted)
+	ut.Assert(t, strings.Contains(w.Body.String(), `"name":"changed"`), "")
+	ut.Equal(t, bar.Name, "b1")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/forbidden", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+}
+
+func TestScopedMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) resource.Middleware {
+		return func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+			calls = append(calls, name)
+			return next()
+		}
+	}
+	deny := func(ctx *resource.Context, next func() *goresterr.APIError) *goresterr.APIError {
+		return goresterr.NewAPIError(goresterr.PermissionDenied, "")
+	}
+
+	mgr := schema.NewSchemaManager()
+	mgr.MustImport(&version, Cluster{}, &clusterHandler{},
+		resource.WithMiddleware(record("cluster"), resource.ForChildren()),
+		resource.WithMiddleware(deny, resource.ForMethods(http.MethodDelete)))
+	mgr.MustImport(&version, Node{}, &nodeHandler{},
+		resource.WithMiddleware(record("node"), resource.ForMethods(http.MethodGet)))
+	s := NewAPIServer(mgr)
+	s.UseMiddleware(record("server"))
+
+	cases := []struct {
+		method string
+		url    string
+		calls  []string
+	}{
+		{http.MethodGet, "/apis/testing/v1/clusters/c1/nodes", []string{"server", "cluster", "node"}},
+		{http.MethodGet, "/apis/testing/v1/clusters", []string{"server", "cluster"}},
+		{http.MethodDelete, "/apis/testing/v1/clusters/c1/nodes/n1", []string{"server", "cluster"}},
+		{http.MethodDelete, "/apis/testing/v1/clusters/c1", []string{"server", "cluster"}},
+	}
+	for _, tc := range cases {
+		calls = nil
+		req, _ := http.NewRequest(tc.method, tc.url, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, calls, tc.calls)
+		if tc.url == "/apis/testing/v1/clusters/c1" {
+			ut.Equal(t, w.Code, goresterr.PermissionDenied.Status)
+		} else {
+			ut.Assert(t, w.Code != goresterr.PermissionDenied.Status, "")
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, &barHandler{bar: bar})
+	s := NewAPIServer(mgr)
+	s.EnableMetrics()
+
+	for _, path := range []string{
+		"/apis/testing/v1/bars/b1",
+		"/apis/testing/v1/bars/b2",
+		"/apis/testing/v1/unknowns",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, MetricsPath, nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	body := w.Body.String()
+	for _, line := range []string{
+		`gorest_requests_total{group="testing",version="v1",kind="bar",verb="get",action="",status="200"} 1`,
+		`gorest_requests_total{group="testing",version="v1",kind="bar",verb="get",action="",status="404"} 1`,
+		`gorest_request_errors_total{group="testing",version="v1",kind="bar",verb="get",action="",code="NotFound"} 1`,
+		`gorest_request_errors_total{group="",version="",kind="",verb="",action="",code="NotFound"} 1`,
+		`gorest_request_duration_seconds_count{group="testing",version="v1",kind="bar",verb="get",action=""} 2`,
+		`gorest_requests_in_flight{group="testing",version="v1",kind="bar",verb="get",action=""} 0`,
+	} {
+		ut.Assert(t, strings.Contains(body, line), "%s isn't in metrics", line)
+	}
+	ut.Assert(t, strings.Contains(body, "b1") == false, "resource id shouldn't be label")
+
+	//metrics is in text format whatever is accepted
+	req, _ = http.NewRequest(http.MethodGet, MetricsPath, nil)
+	req.Header.Set(AcceptKey, "text/plain")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Assert(t, strings.Contains(w.Body.String(), "gorest_requests_total"), "")
+
+	//metrics is protected by server middlewares
+	s.Use(func(ctx *resource.Context) *goresterr.APIError {
+		if ctx.Resource == nil {
+			return goresterr.NewAPIError(goresterr.Unauthorized, "")
+		}
+		return nil
+	})
+	req, _ = http.NewRequest(http.MethodGet, MetricsPath, nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.Unauthorized.Status)
+}
+
+type tracedBarHandler struct {
+	barHandler
+	span trace.Span
+}
+
+func (h *tracedBarHandler) Get(ctx *resource.Context) resource.Resource {
+	h.span = ctx.GetSpan()
+	return h.barHandler.Get(ctx)
+}
+
+func TestTracing(t *testing.T) {
+	bar := &Bar{Name: "b1", Count: 2}
+	bar.SetID("b1")
+	handler := &tracedBarHandler{barHandler: barHandler{bar: bar}}
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Bar{}, handler)
+	s := NewAPIServer(mgr)
+	recorder := trace.NewRecorder()
+	s.SetTracer(recorder)
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b1", nil)
+	req.Header.Set(trace.TraceparentHeader, traceparent)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+
+	spans := make(map[string]*trace.RecordedSpan)
+	var names []string
+	for _, span := range recorder.Spans() {
+		spans[span.Name] = span
+		names = append(names, span.Name)
+		ut.Equal(t, span.Context.TraceID.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	}
+	ut.Equal(t, names, []string{trace.ValidateSpan, trace.ParseSpan, trace.HandlerSpan,
+		trace.LinksSpan, trace.SerializeSpan, trace.RequestSpan})
+
+	root := spans[trace.RequestSpan]
+	ut.Equal(t, root.Parent.String(), "00f067aa0ba902b7")
+	ut.Equal(t, root.Attributes["gorest.kind"], "bar")
+	ut.Equal(t, root.Attributes["http.status_code"], http.StatusOK)
+	ut.Equal(t, spans[trace.ValidateSpan].Parent, spans[trace.ParseSpan].Context.SpanID)
+	for _, name := range []string{trace.ParseSpan, trace.HandlerSpan, trace.LinksSpan, trace.SerializeSpan} {
+		ut.Equal(t, spans[name].Parent, root.Context.SpanID)
+	}
+	ut.Equal(t, handler.span.SpanContext(), spans[trace.HandlerSpan].Context)
+
+	recorder.Reset()
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/bars/b2", nil)
+	s.ServeHTTP(httptest.NewRecorder(), req)
+	spans = make(map[string]*trace.RecordedSpan)
+	for _, span := range recorder.Spans() {
+		spans[span.Name] = span
+	}
+	ut.Assert(t, spans[trace.RequestSpan].Err != nil, "")
+	ut.Equal(t, spans[trace.RequestSpan].Parent, trace.SpanID{})
+}
+
+type panicHandler struct{}
+
+func (h *panicHandler) List(ctx *resource.Context) interface{} {
+	panic("list failed")
+}
+
+//panic when write response
+type unmarshalableFoo struct {
+	Foo
+}
+
+func (f *unmarshalableFoo) MarshalJSON() ([]byte, error) {
+	panic("marshal failed")
+}
+
+func (h *panicHandler) Get(ctx *resource.Context) resource.Resource {
+	foo := &unmarshalableFoo{}
+	foo.SetID(ctx.Resource.GetID())
+	return foo
+}
+
+//panic after response is started
+type streamPanicHandler struct{}
+
+func (h *streamPanicHandler) List(ctx *resource.Context) interface{} {
+	ctx.Response.WriteHeader(http.StatusOK)
+	ctx.Response.Write([]byte("partial"))
+	panic("stream failed")
+}
+
+func TestPanicAfterResponseStarted(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Foo{}, &streamPanicHandler{})
+	s := NewAPIServer(mgr)
+	var logs bytes.Buffer
+	s.SetPanicRecovery(log.New(&logs, "", 0), false)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/foos", nil)
+	w := httptest.NewRecorder()
+	defer func() {
+		ut.Equal(t, recover(), http.ErrAbortHandler)
+		ut.Equal(t, w.Code, http.StatusOK)
+		ut.Equal(t, w.Body.String(), "partial")
+		ut.Assert(t, strings.Contains(logs.String(), "stream failed"), "")
+	}()
+	s.ServeHTTP(w, req)
+	t.Fatal("connection should be aborted")
+}
+
+func TestPanicRecovery(t *testing.T) {
+	mgr := schema.NewSchemaManager()
+	mgr.Import(&version, Foo{}, &panicHandler{})
+	s := NewAPIServer(mgr)
+	s.EnableMetrics()
+	var logs bytes.Buffer
+	s.SetPanicRecovery(log.New(&logs, "", 0), false)
+
+	req, _ := http.NewRequest(http.MethodGet, "/apis/testing/v1/foos", nil)
+	req.Header.Set(RequestIDKey, "req-1")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServerError.Status)
+	ut.Equal(t, w.Header().Get(RequestIDKey), "req-1")
+	var apiErr goresterr.APIError
+	json.Unmarshal(w.Body.Bytes(), &apiErr)
+	ut.Equal(t, apiErr.Code, goresterr.ServerError.Code)
+	ut.Assert(t, strings.Contains(apiErr.Message, "req-1"), "")
+	ut.Assert(t, strings.Contains(logs.String(), "GET /apis/testing/v1/foos"), "")
+	ut.Assert(t, strings.Contains(logs.String(), "list failed"), "")
+	ut.Assert(t, strings.Contains(logs.String(), "panicHandler"), "stack should be logged")
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/foos", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, len(w.Header().Get(RequestIDKey)), 32)
+
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/foos/f1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServerError.Status)
+	ut.Assert(t, strings.Contains(logs.String(), "marshal failed"), "")
+
+	s.SetPanicRecovery(log.New(&logs, "", 0), true)
+	defer func() {
+		ut.Equal(t, recover(), "list failed")
+		req, _ := http.NewRequest(http.MethodGet, MetricsPath, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		body := w.Body.String()
+		ut.Assert(t, strings.Contains(body, `gorest_requests_in_flight{group="testing",version="v1",kind="foo",verb="list",action=""} 0`), "")
+		ut.Assert(t, strings.Contains(body, `gorest_requests_total{group="testing",version="v1",kind="foo",verb="list",action="",status="500"} 3`), "")
+	}()
+	req, _ = http.NewRequest(http.MethodGet, "/apis/testing/v1/foos", nil)
+	s.ServeHTTP(httptest.NewRecorder(), req)
+	t.Fatal("should panic again")
+}
@@ -0,0 +1,22 @@
+package gorest
+
+import (
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
+)
+
+//start span as child of the current span of request, the new span is
+//current until the returned function is called
+func startSpan(ctx *resource.Context, name string) func(*goresterr.APIError) {
+	parent := ctx.Request.Context()
+	spanCtx, span := trace.StartSpan(parent, name)
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+	return func(err *goresterr.APIError) {
+		if err != nil {
+			span.SetError(err)
+		}
+		span.End()
+		ctx.Request = ctx.Request.WithContext(parent)
+	}
+}
@@ -0,0 +1,101 @@
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+type RecordedSpan struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]interface{}
+	Err        error
+}
+
+//tracer which keeps ended spans in memory, mainly used in test
+type Recorder struct {
+	lock  sync.Mutex
+	spans []*RecordedSpan
+}
+
+var _ Tracer = &Recorder{}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) StartSpan(parent SpanContext, name string) Span {
+	sc := SpanContext{
+		TraceID: parent.TraceID,
+		SpanID:  newSpanID(),
+		Sampled: true,
+	}
+	if parent.IsValid() == false {
+		sc.TraceID = newTraceID()
+	}
+	return &recordingSpan{
+		recorder: r,
+		span: RecordedSpan{
+			Name:       name,
+			Context:    sc,
+			Parent:     parent.SpanID,
+			Start:      time.Now(),
+			Attributes: make(map[string]interface{}),
+		},
+	}
+}
+
+//ended spans in the order they end
+func (r *Recorder) Spans() []*RecordedSpan {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*RecordedSpan{}, r.spans...)
+}
+
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	r.spans = nil
+	r.lock.Unlock()
+}
+
+type recordingSpan struct {
+	recorder *Recorder
+	lock     sync.Mutex
+	span     RecordedSpan
+	ended    bool
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return s.span.Context
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.lock.Lock()
+	s.span.Attributes[key] = value
+	s.lock.Unlock()
+}
+
+func (s *recordingSpan) SetError(err error) {
+	s.lock.Lock()
+	s.span.Err = err
+	s.lock.Unlock()
+}
+
+func (s *recordingSpan) End() {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.span.End = time.Now()
+	span := s.span
+	s.lock.Unlock()
+
+	s.recorder.lock.Lock()
+	s.recorder.spans = append(s.recorder.spans, &span)
+	s.recorder.lock.Unlock()
+}
@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const TraceparentHeader = "traceparent"
+
+//spans of each phase of request
+const (
+	RequestSpan = "gorest.request"
+	//read body, decode it and create resource from url
+	ParseSpan = "gorest.parse"
+	//decode body again to validate fields, it's child of parse span
+	ValidateSpan  = "gorest.validate"
+	HandlerSpan   = "gorest.handler"
+	LinksSpan     = "gorest.links"
+	SerializeSpan = "gorest.serialize"
+)
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+//identity of span which is propagated across processes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+//all zero trace id or span id is invalid
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+//value of W3C traceparent header
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+//parse W3C traceparent header version-traceid-parentid-flags, fields
+//after flags are ignored for future versions
+func ParseTraceparent(header string) (SpanContext, error) {
+	var sc SpanContext
+	fields := strings.Split(strings.TrimSpace(header), "-")
+	if len(fields) < 4 {
+		return sc, fmt.Errorf("traceparent %s has too few fields", header)
+	}
+
+	version, err := decodeHex(fields[0], 1)
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(fields) != 4) {
+		return sc, fmt.Errorf("traceparent %s has invalid version", header)
+	}
+
+	traceID, err := decodeHex(fields[1], len(sc.TraceID))
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %s has invalid trace id", header)
+	}
+	spanID, err := decodeHex(fields[2], len(sc.SpanID))
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %s has invalid parent id", header)
+	}
+	flags, err := decodeHex(fields[3], 1)
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %s has invalid flags", header)
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&0x01 == 0x01
+	if sc.IsValid() == false {
+		return SpanContext{}, fmt.Errorf("traceparent %s has all zero id", header)
+	}
+	return sc, nil
+}
+
+//only lower case hex is allowed by W3C
+func decodeHex(s string, size int) ([]byte, error) {
+	if len(s) != 2*size || strings.ToLower(s) != s {
+		return nil, fmt.Errorf("invalid hex %s", s)
+	}
+	return hex.DecodeString(s)
+}
+
+type Span interface {
+	SpanContext() SpanContext
+	SetAttribute(key string, value interface{})
+	//mark the span as failed
+	SetError(err error)
+	End()
+}
+
+//hook to export spans to tracing system, parent is invalid if the span
+//is the root of the trace
+type Tracer interface {
+	StartSpan(parent SpanContext, name string) Span
+}
+
+type activeSpan struct {
+	tracer Tracer
+	span   Span
+}
+
+type activeSpanKey struct{}
+
+//start span as root of the spans in ctx, parent is the span context
+//propagated from client
+func Start(ctx context.Context, tracer Tracer, parent SpanContext, name string) (context.Context, Span) {
+	span := tracer.StartSpan(parent, name)
+	return context.WithValue(ctx, activeSpanKey{}, &activeSpan{tracer, span}), span
+}
+
+//start span as child of the span in ctx, no-op span is returned if
+//there is no span in ctx
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	active, ok := ctx.Value(activeSpanKey{}).(*activeSpan)
+	if ok == false {
+		return ctx, noopSpan{}
+	}
+	return Start(ctx, active.tracer, active.span.SpanContext(), name)
+}
+
+func SpanFromContext(ctx context.Context) Span {
+	if active, ok := ctx.Value(activeSpanKey{}).(*activeSpan); ok {
+		return active.span
+	}
+	return noopSpan{}
+}
+
+//no-op tracer keeps the span context from client, so it's still
+//propagated even spans aren't exported
+type NoopTracer struct{}
+
+func (t NoopTracer) StartSpan(parent SpanContext, name string) Span {
+	return noopSpan{parent}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) SpanContext() SpanContext                   { return s.sc }
+func (s noopSpan) SetAttribute(key string, value interface{}) {}
+func (s noopSpan) SetError(err error)                         {}
+func (s noopSpan) End()                                       {}
+
+func newTraceID() TraceID {
+	var id TraceID
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,61 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+)
+
+func TestTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, sc.TraceID.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ut.Equal(t, sc.SpanID.String(), "00f067aa0ba902b7")
+	ut.Assert(t, sc.Sampled, "")
+	ut.Equal(t, sc.Traceparent(), header)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(invalid)
+		ut.Assert(t, err != nil, "%s should be invalid", invalid)
+	}
+
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	ut.Assert(t, err == nil, "fields of future version should be ignored")
+}
+
+func TestStartSpan(t *testing.T) {
+	_, span := StartSpan(context.Background(), "orphan")
+	ut.Equal(t, span.SpanContext().IsValid(), false)
+
+	recorder := NewRecorder()
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := Start(context.Background(), recorder, parent, "root")
+	_, child := StartSpan(ctx, "child")
+	child.SetAttribute("key", "value")
+	child.End()
+	root.End()
+	ut.Assert(t, SpanFromContext(ctx) == root, "")
+
+	spans := recorder.Spans()
+	ut.Equal(t, len(spans), 2)
+	ut.Equal(t, spans[0].Name, "child")
+	ut.Equal(t, spans[0].Parent, root.SpanContext().SpanID)
+	ut.Equal(t, spans[0].Attributes["key"], "value")
+	ut.Equal(t, spans[1].Name, "root")
+	ut.Equal(t, spans[1].Parent, parent.SpanID)
+	ut.Equal(t, spans[1].Context.TraceID, parent.TraceID)
+	ut.Equal(t, spans[0].Context.TraceID, parent.TraceID)
+
+	_, span = Start(context.Background(), NoopTracer{}, parent, "noop")
+	ut.Equal(t, span.SpanContext(), parent)
+}
@@ -0,0 +1,180 @@
+package gorest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+
+	"github.com/gorilla/websocket"
+
+	goresterr "github.com/ben-han-cn/gorest/error"
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/trace"
+)
+
+var upgrader = websocket.Upgrader{}
+
+func isWatch(ctx *resource.Context) bool {
+	return ctx.Resource.GetID() == "" && ctx.Request.URL.Query().Get("watch") == "true"
+}
+
+func handleWatch(ctx *resource.Context) *goresterr.APIError {
+	handler := ctx.Resource.GetSchema().GetHandler().GetWatchHandler()
+	if handler == nil {
+		return goresterr.NewAPIError(goresterr.NotFound, "no handler for watch")
+	}
+
+	//handler stops publishing when the watch is done
+	reqCtx, cancel := context.WithCancel(ctx.Request.Context())
+	defer cancel()
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+
+	end := startSpan(ctx, trace.HandlerSpan)
+	events, err := handler(ctx)
+	end(err)
+	if err != nil {
+		return err
+	}
+
+	if websocket.IsWebSocketUpgrade(ctx.Request) {
+		return watchWithWebSocket(ctx, events)
+	} else {
+		return watchWithSSE(ctx, events)
+	}
+}
+
+func watchWithSSE(ctx *resource.Context, events <-chan resource.WatchEvent) *goresterr.APIError {
+	flusher, ok := ctx.Response.(http.Flusher)
+	if ok == false {
+		return goresterr.NewAPIError(goresterr.ServerError, "response doesn't support streaming")
+	}
+
+	header := ctx.Response.Header()
+	header.Set(ContentTypeKey, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	ctx.Response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if ok == false {
+				return nil
+			}
+			if event, ok = prepareWatchEvent(ctx, event); ok == false {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(ctx.Response, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func watchWithWebSocket(ctx *resource.Context, events <-chan resource.WatchEvent) *goresterr.APIError {
+	conn, err := upgrader.Upgrade(ctx.Response, ctx.Request, nil)
+	if err != nil {
+		//upgrader has replied the error
+		return nil
+	}
+	defer conn.Close()
+
+	//client doesn't send anything except control messages,
+	//read loop is used to detect closing of the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-ctx.Request.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if ok == false {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return nil
+			}
+			if event, ok = prepareWatchEvent(ctx, event); ok == false {
+				continue
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+//event out of the parent scope of the watch request is ignored,
+//links are added to a copy since the resource is shared by watchers
+func prepareWatchEvent(ctx *resource.Context, event resource.WatchEvent) (resource.WatchEvent, bool) {
+	r := event.Resource
+	if r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil()) {
+		return event, false
+	}
+
+	if isInWatchScope(ctx.Resource, r) == false || ctx.IsResourceVisible(r) == false {
+		return event, false
+	}
+
+	rv := reflect.ValueOf(r)
+	if rv.Kind() == reflect.Ptr {
+		cp := reflect.New(rv.Elem().Type())
+		cp.Elem().Set(rv.Elem())
+		r = cp.Interface().(resource.Resource)
+	}
+
+	//parent of the request carries schema which is needed to generate links
+	schema := ctx.Resource.GetSchema()
+	r.SetType(ctx.Resource.GetType())
+	r.SetParent(ctx.Resource.GetParent())
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		r.SetLinks(nil)
+	}
+	return resource.WatchEvent{
+		Type:     event.Type,
+		Resource: r,
+	}, true
+}
+
+//resource without parent is regarded as being scoped by handler
+func isInWatchScope(collection resource.Resource, r resource.Resource) bool {
+	if r.GetParent() == nil {
+		return true
+	}
+
+	expect := resource.GetAncestors(collection)
+	ancestors := resource.GetAncestors(r)
+	if len(expect) != len(ancestors) {
+		return false
+	}
+
+	for i, ancestor := range ancestors {
+		if ancestor.GetID() != expect[i].GetID() {
+			return false
+		}
+		if typ := ancestor.GetType(); typ != "" && typ != expect[i].GetType() {
+			return false
+		}
+	}
+	return true
+}
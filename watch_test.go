@@ -0,0 +1,141 @@
+package gorest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ut "github.com/ben-han-cn/cement/unittest"
+	"github.com/gorilla/websocket"
+
+	"github.com/ben-han-cn/gorest/resource"
+	"github.com/ben-han-cn/gorest/resource/schema"
+)
+
+type Cluster struct {
+	resource.ResourceBase
+}
+
+type Node struct {
+	resource.ResourceBase
+	Address string `json:"address"`
+}
+
+func (n Node) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Cluster{}}
+}
+
+type clusterHandler struct{}
+
+func (h *clusterHandler) List(ctx *resource.Context) interface{} {
+	return nil
+}
+
+type nodeHandler struct {
+	*resource.EventBroadcaster
+}
+
+func (h *nodeHandler) List(ctx *resource.Context) interface{} {
+	return nil
+}
+
+func newNode(cluster, id, address string) *Node {
+	c := &Cluster{}
+	c.SetID(cluster)
+	c.SetType(resource.DefaultKindName(Cluster{}))
+	n := &Node{Address: address}
+	n.SetID(id)
+	n.SetParent(c)
+	return n
+}
+
+func newWatchServer() (*httptest.Server, *nodeHandler) {
+	mgr := schema.NewSchemaManager()
+	h := &nodeHandler{resource.NewEventBroadcaster()}
+	mgr.MustImport(&version, Cluster{}, &clusterHandler{})
+	mgr.MustImport(&version, Node{}, h)
+	return httptest.NewServer(NewAPIServer(mgr)), h
+}
+
+func waitForWatchers(h *nodeHandler, count int) {
+	for i := 0; i < 100 && h.WatcherCount() != count; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchWithSSE(t *testing.T) {
+	server, h := newWatchServer()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/apis/testing/v1/clusters/c1/nodes?watch=true")
+	ut.Assert(t, err == nil, "watch failed %v", err)
+	defer resp.Body.Close()
+	ut.Equal(t, resp.StatusCode, http.StatusOK)
+	ut.Equal(t, resp.Header.Get(ContentTypeKey), "text/event-stream")
+
+	waitForWatchers(h, 1)
+	h.Publish(resource.Added, newNode("c2", "n0", "10.0.0.0"))
+	h.Publish(resource.Added, newNode("c1", "n1", "10.0.0.1"))
+	h.Publish(resource.Deleted, newNode("c1", "n1", "10.0.0.1"))
+
+	reader := bufio.NewReader(resp.Body)
+	for _, typ := range []resource.EventType{resource.Added, resource.Deleted} {
+		line, _ := reader.ReadString('\n')
+		ut.Equal(t, line, "event: "+string(typ)+"\n")
+		line, _ = reader.ReadString('\n')
+		ut.Assert(t, strings.HasPrefix(line, "data: "), "")
+
+		var event struct {
+			Type   string                 `json:"type"`
+			Object map[string]interface{} `json:"object"`
+		}
+		ut.Assert(t, json.Unmarshal([]byte(line[len("data: "):]), &event) == nil, "")
+		ut.Equal(t, event.Type, string(typ))
+		ut.Equal(t, event.Object["id"], "n1")
+		ut.Equal(t, event.Object["type"], "node")
+		ut.Equal(t, event.Object["address"], "10.0.0.1")
+		links := event.Object["links"].(map[string]interface{})
+		ut.Assert(t, strings.HasSuffix(links["collection"].(string), "/apis/testing/v1/clusters/c1/nodes"), "")
+
+		line, _ = reader.ReadString('\n')
+		ut.Equal(t, line, "\n")
+	}
+}
+
+func TestWatchWithWebSocket(t *testing.T) {
+	server, h := newWatchServer()
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/apis/testing/v1/clusters/c1/nodes?watch=true"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	ut.Assert(t, err == nil, "dial failed %v", err)
+
+	waitForWatchers(h, 1)
+	h.Publish(resource.Modified, newNode("c1", "n2", "10.0.0.2"))
+
+	var event struct {
+		Type   string                 `json:"type"`
+		Object map[string]interface{} `json:"object"`
+	}
+	ut.Assert(t, conn.ReadJSON(&event) == nil, "")
+	ut.Equal(t, event.Type, string(resource.Modified))
+	ut.Equal(t, event.Object["id"], "n2")
+
+	conn.Close()
+	waitForWatchers(h, 0)
+	ut.Equal(t, h.WatcherCount(), 0)
+}
+
+func TestWatchWithoutHandler(t *testing.T) {
+	server, _ := newWatchServer()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/apis/testing/v1/clusters?watch=true")
+	ut.Assert(t, err == nil, "")
+	resp.Body.Close()
+	ut.Equal(t, resp.StatusCode, http.StatusNotFound)
+}